@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Field is an environmental value, such as temperature, salinity or toxin,
+// that diffuses across the grid like pH. Every organism has an ideal value and
+// tolerance for each field, and loses health while outside of its tolerance.
+type Field struct {
+	Name          string    `json:"name"`
+	Min           float64   `json:"min"`
+	Max           float64   `json:"max"`
+	MinInitial    float64   `json:"min_initial"`
+	MaxInitial    float64   `json:"max_initial"`
+	DiffuseFactor float64   `json:"diffuse_factor"`
+	Landscape     Landscape `json:"landscape"` // initial values, scaled between min_initial and max_initial
+	MinIdeal      float64   `json:"min_ideal"`
+	MaxIdeal      float64   `json:"max_ideal"`
+	MinTolerance  float64   `json:"min_tolerance"`
+	MaxTolerance  float64   `json:"max_tolerance"`
+	// HealthChangePerUnhealthy is the health change per cycle, as a percent of
+	// organism size, for each unit outside of an organism's tolerance
+	HealthChangePerUnhealthy float64 `json:"health_change_per_unhealthy"`
+	Hue                      float64 `json:"hue"` // color the field is drawn in, from 0 to 360
+}
+
+// FieldIndex returns the index of the field with the given name, if any
+func (g *Globals) FieldIndex(name string) (int, bool) {
+	for i, field := range g.Fields {
+		if field.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// FieldMetricName returns the name a field is given in metrics, like
+// "salt_water" for a field named "Salt Water"
+func FieldMetricName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
+
+// validateFields checks that every field has a unique name and consistent
+// ranges
+func (g *Globals) validateFields(v *validator) {
+	names := make(map[string]bool, len(g.Fields))
+	// the metrics for each field's traits must not share a name with the
+	// metrics for pH's traits or another field's
+	metrics := map[string]bool{"ideal_ph": true, "ph_tolerance": true}
+	for i, field := range g.Fields {
+		name := field.Name
+		metricName := FieldMetricName(name)
+		if strings.TrimSpace(name) == "" {
+			v.add("", "fields[%d] must have a name", i)
+			name = fmt.Sprintf("fields[%d]", i)
+		} else if names[name] {
+			v.add("", "fields[%d]: name %q is already used", i, name)
+		} else if metricName == "" {
+			v.add("", "fields[%d]: name %q must contain a letter or digit", i, name)
+		} else {
+			for _, metric := range []string{"ideal_" + metricName, metricName + "_tolerance"} {
+				if metrics[metric] {
+					v.add("", "fields[%d]: name %q gives the metric name %s, which is already used", i, name, metric)
+				}
+				metrics[metric] = true
+			}
+		}
+		names[name] = true
+
+		prefix := "field " + name + " "
+		v.ordered(prefix+"min", field.Min, prefix+"max", field.Max)
+		v.ordered(prefix+"min_initial", field.MinInitial, prefix+"max_initial", field.MaxInitial)
+		v.check(field.MinInitial >= field.Min && field.MaxInitial <= field.Max,
+			"field %s: min_initial and max_initial (%g to %g) must be within min and max (%g to %g)",
+			name, field.MinInitial, field.MaxInitial, field.Min, field.Max)
+		v.fraction(prefix+"diffuse_factor", field.DiffuseFactor)
+		v.ordered(prefix+"min_ideal", field.MinIdeal, prefix+"max_ideal", field.MaxIdeal)
+		v.check(field.MinTolerance >= 0, "field %s: min_tolerance must not be negative, found %g", name, field.MinTolerance)
+		v.ordered(prefix+"min_tolerance", field.MinTolerance, prefix+"max_tolerance", field.MaxTolerance)
+		validateLandscape(v, "field "+name+" landscape", field.Landscape)
+	}
+}
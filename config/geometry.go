@@ -0,0 +1,67 @@
+package config
+
+const (
+	// PanelWidth is the width in pixels of the panel drawn beside the grid
+	PanelWidth = 400
+	// MinWindowHeight is the smallest window height that fits the panel
+	MinWindowHeight = 800
+
+	// defaultMaxWindowWidth and defaultMaxWindowHeight bound the window size
+	// used to choose a unit size when no window size is given
+	defaultMaxWindowWidth  = 1400
+	defaultMaxWindowHeight = 800
+)
+
+// UnitSize returns the size in pixels of a single grid unit. This is
+// grid_unit_size if set, or otherwise the largest size that fits the grid
+// beside the panel in the window.
+func (g *Globals) UnitSize() int {
+	if g.GridUnitSize > 0 {
+		return g.GridUnitSize
+	}
+	maxWidth, maxHeight := defaultMaxWindowWidth, defaultMaxWindowHeight
+	if g.ScreenWidth > 0 {
+		maxWidth = g.ScreenWidth
+	}
+	if g.ScreenHeight > 0 {
+		maxHeight = g.ScreenHeight
+	}
+	if g.GridUnitsWide <= 0 || g.GridUnitsHigh <= 0 {
+		return 1
+	}
+	size := (maxWidth - PanelWidth) / g.GridUnitsWide
+	if heightSize := maxHeight / g.GridUnitsHigh; heightSize < size {
+		size = heightSize
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// GridWidth returns the width of the grid in pixels
+func (g *Globals) GridWidth() int {
+	return g.GridUnitsWide * g.UnitSize()
+}
+
+// GridHeight returns the height of the grid in pixels
+func (g *Globals) GridHeight() int {
+	return g.GridUnitsHigh * g.UnitSize()
+}
+
+// WindowSize returns the size of the window in pixels. Each dimension is
+// screen_width or screen_height if set, or otherwise just large enough to fit
+// the panel and grid.
+func (g *Globals) WindowSize() (int, int) {
+	width, height := g.ScreenWidth, g.ScreenHeight
+	if width <= 0 {
+		width = PanelWidth + g.GridWidth()
+	}
+	if height <= 0 {
+		height = g.GridHeight()
+		if height < MinWindowHeight {
+			height = MinWindowHeight
+		}
+	}
+	return width, height
+}
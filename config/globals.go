@@ -1,94 +1,51 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
-	"os"
-)
-
-var defaultFilePath = "settings/default.json"
-var constants *Globals
-
-// SetGlobals allows a one-time initialization of all globally-referenced constants
-func SetGlobals(g *Globals) {
-	if constants != nil {
-		return
-	}
-	constants = g
-}
 
-func GridUnitSize() int                        { return constants.GridUnitSize }
-func GridWidth() int                           { return constants.GridWidth }
-func GridHeight() int                          { return constants.GridHeight }
-func GridUnitsWide() int                       { return constants.GridUnitsWide }
-func GridUnitsHigh() int                       { return constants.GridUnitsHigh }
-func ScreenWidth() int                         { return constants.ScreenWidth }
-func ScreenHeight() int                        { return constants.ScreenHeight }
-func PopulationUpdateInterval() int            { return constants.PopulationUpdateInterval }
-func InitialOrganisms() int                    { return constants.InitialOrganisms }
-func InitialFood() int                         { return constants.InitialFood }
-func ChanceToAddFoodItem() float64             { return constants.ChanceToAddFoodItem }
-func MaxFoodValue() int                        { return constants.MaxFoodValue }
-func MinFoodValue() int                        { return constants.MinFoodValue }
-func MinPh() float64                           { return constants.MinPh }
-func MaxPh() float64                           { return constants.MaxPh }
-func MinInitialPh() float64                    { return constants.MinInitialPh }
-func MaxInitialPh() float64                    { return constants.MaxInitialPh }
-func MaxCyclesBetweenSpawns() int              { return constants.MaxCyclesBetweenSpawns }
-func MinSpawnHealth() float64                  { return constants.MinSpawnHealth }
-func MaxSpawnHealthPercent() float64           { return constants.MaxSpawnHealthPercent }
-func InitialDecisionTreeMutations() int        { return constants.InitialDecisionTreeMutations }
-func MinChanceToMutateDecisionTree() float64   { return constants.MinChanceToMutateDecisionTree }
-func MaxChanceToMutateDecisionTree() float64   { return constants.MaxChanceToMutateDecisionTree }
-func MinOrganisms() int                        { return constants.MinOrganisms }
-func MaxOrganisms() int                        { return constants.MaxOrganisms }
-func GrowthFactor() float64                    { return constants.GrowthFactor }
-func MaximumMaxSize() float64                  { return constants.MaximumMaxSize }
-func MinimumMaxSize() float64                  { return constants.MinimumMaxSize }
-func MinIdealPh() float64                      { return constants.MinIdealPh }
-func MaxIdealPh() float64                      { return constants.MaxIdealPh }
-func MinPhTolerance() float64                  { return constants.MinPhTolerance }
-func MaxPhTolerance() float64                  { return constants.MaxPhTolerance }
-func MaxOrganismPhGrowthEffect() float64       { return constants.MaxOrganismPhGrowthEffect }
-func PhIncrementToDisplay() float64            { return constants.PhIncrementToDisplay }
-func PhDiffuseFactor() float64                 { return constants.PhDiffuseFactor }
-func UsePools() bool                           { return constants.UsePools }
-func PoolWidth() int                           { return constants.PoolWidth }
-func PoolHeight() int                          { return constants.PoolHeight }
-func HealthChangeFromChemosynthesis() float64  { return constants.HealthChangeFromChemosynthesis }
-func HealthChangeFromTurning() float64         { return constants.HealthChangeFromTurning }
-func HealthChangeFromMoving() float64          { return constants.HealthChangeFromMoving }
-func HealthChangeFromEatingAttempt() float64   { return constants.HealthChangeFromEatingAttempt }
-func HealthChangeFromAttacking() float64       { return constants.HealthChangeFromAttacking }
-func HealthChangeInflictedByAttack() float64   { return constants.HealthChangeInflictedByAttack }
-func HealthChangeFromFeeding() float64         { return constants.HealthChangeFromFeeding }
-func HealthChangePerDecisionTreeNode() float64 { return constants.HealthChangePerDecisionTreeNode }
-func HealthChangePerUnhealthyPh() float64      { return constants.HealthChangePerCycleUnhealthyPh }
-func MaxDecisionTreeSize() int                 { return constants.MaxDecisionTreeSize }
+	"github.com/Zebbeni/protozoa/settings"
+)
 
+// Globals contains all parameters of a simulation. Each simulation owns its
+// own Globals, so simulations with different settings can run side by side.
 type Globals struct {
-	// Drawing parameters
-	GridUnitSize  int `json:"grid_unit_size"`
-	GridWidth     int `json:"grid_width"`
-	GridHeight    int `json:"grid_height"`
+	// World size in grid units
 	GridUnitsWide int `json:"grid_units_wide"`
 	GridUnitsHigh int `json:"grid_units_high"`
-	ScreenWidth   int `json:"screen_width"`
-	ScreenHeight  int `json:"screen_height"`
+
+	// Drawing parameters, each 0 to size automatically (see UnitSize and
+	// WindowSize)
+	GridUnitSize int `json:"grid_unit_size"`
+	ScreenWidth  int `json:"screen_width"`
+	ScreenHeight int `json:"screen_height"`
 
 	// Statistics parameters
 	PopulationUpdateInterval int `json:"population_update_interval"`
 
 	// Environment parameters
-	InitialOrganisms    int     `json:"initial_organisms"`
-	InitialFood         int     `json:"initial_food"`
-	ChanceToAddFoodItem float64 `json:"chance_to_add_food_item"`
-	MaxFoodValue        int     `json:"max_food_value"`
-	MinFoodValue        int     `json:"min_food_value"`
-	MinPh               float64 `json:"min_ph"`
-	MaxPh               float64 `json:"max_ph"`
-	MinInitialPh        float64 `json:"min_initial_ph"`
-	MaxInitialPh        float64 `json:"max_initial_ph"`
+	InitialOrganisms    int       `json:"initial_organisms"`
+	InitialFood         int       `json:"initial_food"`
+	ChanceToAddFoodItem float64   `json:"chance_to_add_food_item"`
+	MaxFoodValue        int       `json:"max_food_value"`
+	MinFoodValue        int       `json:"min_food_value"`
+	MinPh               float64   `json:"min_ph"`
+	MaxPh               float64   `json:"max_ph"`
+	MinInitialPh        float64   `json:"min_initial_ph"`
+	MaxInitialPh        float64   `json:"max_initial_ph"`
+	InitialPhLandscape  Landscape `json:"initial_ph_landscape"`
+	Vents               []Vent    `json:"vents"`  // sources and sinks of pH or other fields
+	Fields              []Field   `json:"fields"` // diffusing values other than pH
+
+	// Light parameters. Light scales the health gained from chemosynthesis.
+	LightLandscape Landscape `json:"light_landscape"` // light levels, scaled between min_light and max_light
+	MinLight       float64   `json:"min_light"`
+	MaxLight       float64   `json:"max_light"`
+	DayLength      int       `json:"day_length"`  // cycles from one noon to the next, 0 for constant daylight
+	NightLight     float64   `json:"night_light"` // fraction of noon light left at midnight
+	MaxShade       float64   `json:"max_shade"`   // fraction of light blocked around an organism of maximum_max_size
 
 	// Organism parameters
 	MaxCyclesBetweenSpawns        int     `json:"max_cycles_between_spawns"`
@@ -115,6 +72,9 @@ type Globals struct {
 	UsePools                      bool    `json:"use_pools"`
 	PoolWidth                     int     `json:"pool_width"`
 	PoolHeight                    int     `json:"pool_height"`
+	WallMap                       string  `json:"wall_map"`         // PNG or text file of walls, "" for none
+	BoundedWorld                  bool    `json:"bounded_world"`    // hard edges instead of wrapping around
+	EightDirections               bool    `json:"eight_directions"` // let organisms face and move diagonally
 
 	// Health parameters (percent of organism size)
 	HealthChangeFromChemosynthesis  float64 `json:"health_change_from_chemosynthesis"`
@@ -126,36 +86,73 @@ type Globals struct {
 	HealthChangeFromFeeding         float64 `json:"health_change_from_feeding"`
 	HealthChangePerDecisionTreeNode float64 `json:"health_change_per_decision_tree_node"`
 	HealthChangePerCycleUnhealthyPh float64 `json:"health_change_per_unhealthy_ph"`
+
+	// End conditions (a simulation always ends when no organisms are left)
+	EndMaxCycles        int     `json:"end_max_cycles"`        // 0 for no limit
+	EndPopulationAbove  int     `json:"end_population_above"`  // 0 to disable
+	EndPopulationBelow  int     `json:"end_population_below"`  // 0 to disable
+	EndPopulationCycles int     `json:"end_population_cycles"` // consecutive cycles a population threshold must be passed
+	EndLineageDominance float64 `json:"end_lineage_dominance"` // fraction of organisms descended from one ancestor, 0 to disable
+	EndMinAveragePh     float64 `json:"end_min_average_ph"`    // end if the average pH leaves this band,
+	EndMaxAveragePh     float64 `json:"end_max_average_ph"`    // disabled if both are 0
+
+	// Timeline of regimes that change settings at given cycles or on a
+	// repeating schedule
+	Timeline []Regime `json:"timeline"`
 }
 
-func LoadFile(filePath string) io.Reader {
-	file, err := os.Open(filePath)
-	if err != nil {
-		panic("failed to read config file")
-	}
-	return file
+// LoadFile reads a config file, applying its settings over the defaults and
+// any files it extends. Any unknown or invalid settings are reported together
+// in a ValidationError.
+func LoadFile(filePath string) (*Globals, error) {
+	g := GetDefaultGlobals()
+	v := &validator{}
+	applyFile(filePath, &g, v, make(map[string]bool))
+	return checkLoaded(&g, v)
 }
 
+// GetDefaultGlobals returns the default config
 func GetDefaultGlobals() Globals {
-	defaultFile := LoadFile(defaultFilePath)
-	g := applyGlobalsFromJson(defaultFile, Globals{})
+	g, err := applyGlobalsFromJson(bytes.NewReader(settings.Default), Globals{})
+	if err != nil {
+		// the defaults are built in, so this can only be a programming error
+		panic(fmt.Sprintf("default settings are invalid: %v", err))
+	}
 	return *g
 }
 
-func LoadGlobals(file io.Reader) *Globals {
-	defaults := GetDefaultGlobals()
-	g := applyGlobalsFromJson(file, defaults)
-	return g
+// LoadGlobals reads settings in JSON format, applying them over the defaults
+func LoadGlobals(file io.Reader) (*Globals, error) {
+	return applyGlobalsFromJson(file, GetDefaultGlobals())
 }
 
-func applyGlobalsFromJson(file io.Reader, globals Globals) *Globals {
-	g := globals
-	decoder := json.NewDecoder(file)
-	err := decoder.Decode(&g)
+func applyGlobalsFromJson(file io.Reader, globals Globals) (*Globals, error) {
+	data, err := io.ReadAll(file)
 	if err != nil {
-		panic("failed to read globals from file")
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	g := globals
+	v := &validator{}
+	if settings, ok := parseSettings("", data, v); ok {
+		decodeStrict("", settings, &g, v)
+	}
+	return checkLoaded(&g, v)
+}
+
+// checkLoaded validates a newly-loaded config along with the files it names,
+// returning a ValidationError listing every problem found while loading it
+func checkLoaded(g *Globals, v *validator) (*Globals, error) {
+	g.validate(v)
+	g.validateWallMap(v)
+	validateLandscapeImage(v, "initial_ph_landscape", g.InitialPhLandscape)
+	validateLandscapeImage(v, "light_landscape", g.LightLandscape)
+	for _, field := range g.Fields {
+		validateLandscapeImage(v, "field "+field.Name+" landscape", field.Landscape)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
 	}
-	return &g
+	return g, nil
 }
 
 func DumpGlobals(g *Globals, file io.Writer) {
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSettingsFilesAreValid(t *testing.T) {
+	paths, _ := filepath.Glob("../settings/*.json")
+	if len(paths) == 0 {
+		t.Fatal("found no settings files")
+	}
+	for _, path := range paths {
+		if _, err := LoadFile(path); err != nil {
+			t.Errorf("expected %s to load, found %v", path, err)
+		}
+	}
+}
+
+func TestLoadGlobalsReportsEveryProblem(t *testing.T) {
+	_, err := LoadGlobals(strings.NewReader(`{
+		"chance_to_add_organism": 0.1,
+		"max_organisms": "many",
+		"initial_food": 1.5
+	}`))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, found %v", err)
+	}
+	expected := []string{
+		`unknown setting "chance_to_add_organism"`,
+		"initial_food must be a whole number, found number 1.5",
+		"max_organisms must be a whole number, found string",
+	}
+	if strings.Join(validationErr.Problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected problems %q, found %q", expected, validationErr.Problems)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"min_ph": 9, "max_ph": 2, "screen_width": 500}`))
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) < 3 {
+		t.Errorf("expected screen width and pH range problems, found %v", err)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"min_food_value": 0, "max_food_value": 0}`))
+	if err == nil || !strings.Contains(err.Error(), "max_food_value") {
+		t.Errorf("expected a max food value of 0 to be reported, found %v", err)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"initial_ph_landscape": {"generator": "hills"}}`))
+	if err == nil || !strings.Contains(err.Error(), `initial_ph_landscape: unknown generator "hills"`) {
+		t.Errorf("expected an unknown landscape generator to be reported, found %v", err)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"initial_ph_landscape": {"generator": "noise", "sead": 3}}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown setting "initial_ph_landscape.sead"`) {
+		t.Errorf("expected a misspelled landscape field to be reported, found %v", err)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"vents": [{"x": 1, "y": 1, "strenght": 0.1}]}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown setting "vents[0].strenght"`) {
+		t.Errorf("expected a misspelled vent field to be reported, found %v", err)
+	}
+}
+
+func TestGeometryIsDerivedFromWorldSize(t *testing.T) {
+	tests := []struct {
+		settings                string
+		unitSize, width, height int
+	}{
+		{`{}`, 5, 1400, 800},
+		{`{"grid_units_wide": 125, "grid_units_high": 100}`, 8, 1400, 800},
+		{`{"grid_units_wide": 37, "grid_units_high": 13}`, 27, 1399, 800},
+		{`{"grid_units_wide": 100, "grid_units_high": 100, "screen_height": 1000}`, 10, 1400, 1000},
+		{`{"grid_units_wide": 50, "grid_units_high": 50, "grid_unit_size": 3}`, 3, 550, 800},
+	}
+	for _, test := range tests {
+		g, err := LoadGlobals(strings.NewReader(test.settings))
+		if err != nil {
+			t.Fatalf("%s: %v", test.settings, err)
+		}
+		width, height := g.WindowSize()
+		if g.UnitSize() != test.unitSize || width != test.width || height != test.height {
+			t.Errorf("%s: expected unit size %d in a %dx%d window, found %d in %dx%d",
+				test.settings, test.unitSize, test.width, test.height, g.UnitSize(), width, height)
+		}
+	}
+}
+
+func TestUnknownFieldsAreFoundWithinSettings(t *testing.T) {
+	type wave struct {
+		Period int `json:"period"`
+	}
+	type regime struct {
+		Name  string          `json:"name"`
+		Waves []wave          `json:"waves"`
+		Set   map[string]wave `json:"set"`
+	}
+	raw := []byte(`[{"name": "a", "waves": [{"period": 1}, {"perod": 2}], "set": {"x": {"Period": 3, "max": 4}}}, {"nmae": "b"}]`)
+	unknown := unknownFields("regimes", raw, reflect.TypeOf([]regime{}))
+	expected := []string{"regimes[0].set.x.max", "regimes[0].waves[1].perod", "regimes[1].nmae"}
+	if strings.Join(unknown, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected unknown fields %q, found %q", expected, unknown)
+	}
+}
+
+func TestTimelineIsValidated(t *testing.T) {
+	for _, c := range []struct {
+		settings string
+		problem  string
+	}{
+		{`{"timeline": [{"name": "tide", "waves": [{"key": "max_organisms", "perod": 10}]}]}`, "timeline[0].waves[0].perod"},
+		{`{"timeline": [{"name": "tide", "period": "often"}]}`, "period must be a whole number, found string"},
+		{`{"timeline": [{"name": "flood", "settings": {"chance_to_add_food_item": 2}}]}`, "chance_to_add_food_item must be between"},
+		{`{"timeline": [{"name": "drift", "settings": {"world_width": 100}}]}`, `"world_width" can't be changed`},
+	} {
+		_, err := LoadGlobals(strings.NewReader(c.settings))
+		if err == nil || !strings.Contains(err.Error(), c.problem) {
+			t.Errorf("expected %s to report %q, found %v", c.settings, c.problem, err)
+		}
+	}
+}
+
+func TestFieldMetricNamesMustBeUnique(t *testing.T) {
+	for _, c := range []struct {
+		fields  string
+		problem string
+	}{
+		{`[{"name": "Temp"}, {"name": "temp"}]`, `fields[1]: name "temp" gives the metric name ideal_temp`},
+		{`[{"name": "salt water"}, {"name": "salt-water"}]`, `fields[1]: name "salt-water" gives the metric name ideal_salt_water`},
+		{`[{"name": "PH"}]`, `fields[0]: name "PH" gives the metric name ideal_ph`},
+		{`[{"name": "ideal_x"}, {"name": "x_tolerance"}]`, `fields[1]: name "x_tolerance" gives the metric name ideal_x_tolerance`},
+		{`[{"name": "--"}]`, `fields[0]: name "--" must contain a letter or digit`},
+	} {
+		_, err := LoadGlobals(strings.NewReader(`{"fields": ` + c.fields + `}`))
+		if err == nil || !strings.Contains(err.Error(), c.problem) {
+			t.Errorf("expected fields %s to report %q, found %v", c.fields, c.problem, err)
+		}
+	}
+	_, err := LoadGlobals(strings.NewReader(`{"fields": [{"name": "Salt Water"}, {"name": "temperature"}]}`))
+	if err != nil && strings.Contains(err.Error(), "metric name") {
+		t.Errorf("expected distinct field names to be accepted, found %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"os"
+	"path/filepath"
+)
+
+// Landscape generators
+const (
+	LandscapeUniform  = "uniform"
+	LandscapeRandom   = "random"
+	LandscapeGradient = "gradient"
+	LandscapeRadial   = "radial"
+	LandscapeNoise    = "noise"
+	LandscapeImage    = "image"
+)
+
+// Landscape describes how to generate the starting levels of a value, such as
+// pH, across the grid. Levels run from 0 to 1 and are scaled to the value's
+// initial range by whatever uses them.
+type Landscape struct {
+	Generator string  `json:"generator"` // "uniform" (the default), "random", "gradient", "radial", "noise" or "image"
+	Angle     float64 `json:"angle"`     // gradient direction in degrees, 0 rises left to right and 90 top to bottom
+	Scale     float64 `json:"scale"`     // size of the largest noise features in grid units
+	Seed      int64   `json:"seed"`      // seed for random and noise landscapes
+	Image     string  `json:"image"`     // greyscale image, black for the lowest level and white for the highest
+	Invert    bool    `json:"invert"`    // swap the lowest and highest levels
+}
+
+// LevelImage is a greyscale image read as levels from 0 to 1
+type LevelImage struct {
+	Width, Height int
+	levels        []float64
+}
+
+// LevelAt returns the level of the image at the given pixel coordinates
+func (m *LevelImage) LevelAt(x, y int) float64 {
+	return m.levels[y*m.Width+x]
+}
+
+// LoadLevelImage reads a greyscale level image from a PNG file. Colors are
+// converted to grey and transparent pixels are black.
+func LoadLevelImage(path string) (*LevelImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read landscape image: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read landscape image %s: %w", path, err)
+	}
+	bounds := img.Bounds()
+	m := &LevelImage{Width: bounds.Dx(), Height: bounds.Dy()}
+	m.levels = make([]float64, m.Width*m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			gray := color.Gray16Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray16)
+			m.levels[y*m.Width+x] = float64(gray.Y) / 0xffff
+		}
+	}
+	return m, nil
+}
+
+// resolveImage makes the path of a landscape image named by a settings file
+// relative to the directory of that settings file, leaving it alone if the
+// file didn't name an image
+func (l *Landscape) resolveImage(settingsPath string, named Landscape) {
+	if named.Image != "" && !filepath.IsAbs(named.Image) {
+		l.Image = filepath.Join(filepath.Dir(settingsPath), named.Image)
+	}
+}
+
+// validateLandscape checks that a landscape names a known generator with the
+// parameters it needs
+func validateLandscape(v *validator, name string, l Landscape) {
+	switch l.Generator {
+	case "", LandscapeUniform, LandscapeRandom, LandscapeGradient, LandscapeRadial:
+	case LandscapeNoise:
+		v.check(l.Scale > 0, "%s: noise scale must be greater than 0, found %g", name, l.Scale)
+	case LandscapeImage:
+		v.check(l.Image != "", "%s: image generator needs an image", name)
+	default:
+		v.add(name, "unknown generator %q (use uniform, random, gradient, radial, noise or image)", l.Generator)
+	}
+}
+
+// validateLandscapeImage checks that a landscape's image, if it uses one, can
+// be read
+func validateLandscapeImage(v *validator, name string, l Landscape) {
+	if l.Generator != LandscapeImage || l.Image == "" {
+		return
+	}
+	if _, err := LoadLevelImage(l.Image); err != nil {
+		v.add(name, "%v", err)
+	}
+}
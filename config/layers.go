@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// extendsKey names the settings file a settings file inherits from
+	extendsKey = "extends"
+	// envPrefix starts the names of environment variables that override
+	// settings, e.g. PROTOZOA_MAX_ORGANISMS
+	envPrefix = "PROTOZOA_"
+)
+
+// pathSettings lists the settings that name files
+var pathSettings = []string{"wall_map"}
+
+// landscapeSettings lists the settings that are landscapes, which may name
+// image files
+var landscapeSettings = []string{"initial_ph_landscape", "light_landscape"}
+
+// Load returns the config given by the options, built up in layers: the
+// defaults, then any files extended by the config file, then the config file
+// itself, then environment variable overrides, and finally -set overrides.
+// Problems in every layer are reported together in a ValidationError.
+func Load(opts *Options) (*Globals, error) {
+	g := GetDefaultGlobals()
+	v := &validator{}
+	if opts.ConfigFile != "" {
+		applyFile(opts.ConfigFile, &g, v, make(map[string]bool))
+	}
+	applyEnvironment(os.Environ(), &g, v)
+	applyOverrides(opts.Overrides, &g, v)
+	return checkLoaded(&g, v)
+}
+
+// OverrideSources lists every source of settings given by the options or the
+// environment that would override the defaults. Resumed simulations keep the
+// settings they were saved with, so none can be given with -resume.
+func OverrideSources(opts *Options) []string {
+	return overrideSources(opts, os.Environ())
+}
+
+func overrideSources(opts *Options, environ []string) []string {
+	var sources []string
+	if opts.ConfigFile != "" {
+		sources = append(sources, "-config "+opts.ConfigFile)
+	}
+	for _, variable := range environ {
+		if name, _, _ := strings.Cut(variable, "="); strings.HasPrefix(name, envPrefix) {
+			sources = append(sources, "environment variable "+name)
+		}
+	}
+	for _, override := range opts.Overrides {
+		sources = append(sources, "-set "+override)
+	}
+	return sources
+}
+
+// applyFile applies the settings in a file to a config, after first applying
+// the file it extends, if any. Relative paths to extended files are resolved
+// from the directory of the file extending them.
+func applyFile(path string, g *Globals, v *validator, visited map[string]bool) {
+	absolute, err := filepath.Abs(path)
+	if err == nil && visited[absolute] {
+		v.add(path, "extends itself")
+		return
+	}
+	visited[absolute] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.add(path, "failed to read config file: %v", err)
+		return
+	}
+	settings, ok := parseSettings(path, data, v)
+	if !ok {
+		return
+	}
+	if raw, ok := settings[extendsKey]; ok {
+		delete(settings, extendsKey)
+		var base string
+		if err = json.Unmarshal(raw, &base); err != nil || base == "" {
+			v.add(path, "%s must be the path of another settings file", extendsKey)
+		} else {
+			if !filepath.IsAbs(base) {
+				base = filepath.Join(filepath.Dir(path), base)
+			}
+			applyFile(base, g, v, visited)
+		}
+	}
+	decodeStrict(path, settings, g, v)
+	resolvePaths(path, settings, g)
+}
+
+// resolvePaths makes the relative paths of files named by a settings file
+// relative to the directory of that settings file
+func resolvePaths(path string, settings map[string]json.RawMessage, g *Globals) {
+	for _, key := range pathSettings {
+		var file string
+		if err := json.Unmarshal(settings[key], &file); err != nil || file == "" || filepath.IsAbs(file) {
+			continue
+		}
+		if field, ok := g.field(key); ok {
+			field.SetString(filepath.Join(filepath.Dir(path), file))
+		}
+	}
+	for _, key := range landscapeSettings {
+		var landscape Landscape
+		if field, ok := g.field(key); ok && json.Unmarshal(settings[key], &landscape) == nil {
+			field.Addr().Interface().(*Landscape).resolveImage(path, landscape)
+		}
+	}
+	var fields []Field
+	if json.Unmarshal(settings["fields"], &fields) == nil {
+		for i := 0; i < len(fields) && i < len(g.Fields); i++ {
+			g.Fields[i].Landscape.resolveImage(path, fields[i].Landscape)
+		}
+	}
+}
+
+// applyEnvironment applies settings from environment variables named after
+// them, so PROTOZOA_MAX_ORGANISMS=100 sets max_organisms to 100
+func applyEnvironment(environ []string, g *Globals, v *validator) {
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, envPrefix))
+		source := fmt.Sprintf("environment variable %s", name)
+		decodeStrict(source, map[string]json.RawMessage{key: settingValue(value)}, g, v)
+	}
+}
+
+// applyOverrides applies settings given as key=value pairs
+func applyOverrides(overrides []string, g *Globals, v *validator) {
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok || key == "" {
+			v.add("-set "+override, "expected key=value")
+			continue
+		}
+		decodeStrict("-set", map[string]json.RawMessage{key: settingValue(value)}, g, v)
+	}
+}
+
+// settingValue returns a value given on the command line or in the
+// environment as JSON, treating it as a string if it isn't valid JSON already
+func settingValue(value string) json.RawMessage {
+	if json.Valid([]byte(value)) {
+		return json.RawMessage(value)
+	}
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayersApplyInOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeSettings(t, filepath.Join(dir, "base.json"), `{"max_organisms": 100, "initial_food": 10, "min_organisms": 5}`)
+	writeSettings(t, filepath.Join(dir, "child.json"), `{"extends": "base.json", "max_organisms": 200, "initial_food": 20}`)
+
+	g := GetDefaultGlobals()
+	v := &validator{}
+	applyFile(filepath.Join(dir, "child.json"), &g, v, make(map[string]bool))
+	applyEnvironment([]string{"PROTOZOA_INITIAL_FOOD=30", "HOME=/root"}, &g, v)
+	applyOverrides([]string{"max_organisms=300"}, &g, v)
+	if err := v.err(); err != nil {
+		t.Fatal(err)
+	}
+	if g.MinOrganisms != 5 || g.InitialFood != 30 || g.MaxOrganisms != 300 {
+		t.Errorf("expected min 5, food 30 and max 300, found %d, %d and %d", g.MinOrganisms, g.InitialFood, g.MaxOrganisms)
+	}
+}
+
+func TestExtendsCycleIsReported(t *testing.T) {
+	dir := t.TempDir()
+	writeSettings(t, filepath.Join(dir, "a.json"), `{"extends": "b.json"}`)
+	writeSettings(t, filepath.Join(dir, "b.json"), `{"extends": "a.json"}`)
+	_, err := LoadFile(filepath.Join(dir, "a.json"))
+	if err == nil || !strings.Contains(err.Error(), "extends itself") {
+		t.Errorf("expected a cycle to be reported, found %v", err)
+	}
+}
+
+func TestOverrideSourcesAreListed(t *testing.T) {
+	opts := &Options{ConfigFile: "big.json", Overrides: settingList{"max_organisms=10"}}
+	sources := overrideSources(opts, []string{"PROTOZOA_INITIAL_FOOD=30", "HOME=/root"})
+	expected := []string{"-config big.json", "environment variable PROTOZOA_INITIAL_FOOD", "-set max_organisms=10"}
+	if strings.Join(sources, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected sources %q, found %q", expected, sources)
+	}
+	if sources = overrideSources(&Options{}, []string{"HOME=/root"}); len(sources) != 0 {
+		t.Errorf("expected no sources, found %q", sources)
+	}
+}
+
+func writeSettings(t *testing.T, path, data string) {
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLandscapeImagesAreFoundFromTheirSettingsFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSettings(t, filepath.Join(dir, "sub", "base.json"), `{"initial_ph_landscape": {"generator": "image", "image": "ph.png"}}`)
+	writeSettings(t, filepath.Join(dir, "child.json"), `{"extends": "sub/base.json", "initial_ph_landscape": {"invert": true}}`)
+
+	g := GetDefaultGlobals()
+	v := &validator{}
+	applyFile(filepath.Join(dir, "child.json"), &g, v, make(map[string]bool))
+	if err := v.err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := filepath.Join(dir, "sub", "ph.png")
+	if g.InitialPhLandscape.Image != expected || !g.InitialPhLandscape.Invert {
+		t.Errorf("expected inverted image %s, found %+v", expected, g.InitialPhLandscape)
+	}
+}
@@ -1,27 +1,70 @@
 package config
 
-import "flag"
+import (
+	"flag"
+	"strings"
+)
 
 type Options struct {
-	ConfigFile  string
-	DumpConfig  bool
-	IsHeadless  bool
-	IsDebugging bool
-	TrialCount  int
-	Seed        int
+	ConfigFile       string
+	Overrides        settingList
+	DumpConfig       bool
+	IsHeadless       bool
+	IsDebugging      bool
+	IsDeterministic  bool
+	TrialCount       int
+	Parallel         int
+	Seed             int
+	ResumeFile       string
+	SnapshotFile     string
+	SnapshotInterval int
+	RecordFile       string
+	KeyframeInterval int
+	ReplayFile       string
+	MetricsFile      string
+	SweepFile        string
+	SweepOutFile     string
+	ChangesFile      string
+	ChangesOutFile   string
 }
 
 func GetOptions() *Options {
 	opts := Options{}
 
-	flag.BoolVar(&opts.DumpConfig, "dump-config", false, "Dump the default config to stdout")
+	flag.BoolVar(&opts.DumpConfig, "dump-config", false, "Dump the effective config, after all overrides, to stdout")
 	flag.BoolVar(&opts.IsDebugging, "debug", false, "Run simulation and display debug statistics")
 	flag.BoolVar(&opts.IsHeadless, "headless", false, "Run simulation without visualization")
+	flag.BoolVar(&opts.IsDeterministic, "deterministic", false, "Process organisms in a fixed order so runs with the same seed are reproducible")
 	flag.IntVar(&opts.TrialCount, "trials", 1, "Number of trials to run")
+	flag.IntVar(&opts.Parallel, "parallel", 1, "Number of headless trials to run at the same time")
 	flag.IntVar(&opts.Seed, "seed", 0, "Set the random seed")
 	flag.StringVar(&opts.ConfigFile, "config", "", "Config file in JSON format")
+	flag.Var(&opts.Overrides, "set", "Override a config setting as key=value (can be repeated)")
+	flag.StringVar(&opts.ResumeFile, "resume", "", "Snapshot file to resume a saved simulation from")
+	flag.StringVar(&opts.SnapshotFile, "snapshot", "protozoa.snapshot", "File to save simulation snapshots to")
+	flag.IntVar(&opts.SnapshotInterval, "snapshot-interval", 0, "Number of cycles between automatic snapshots (0 to disable)")
+	flag.StringVar(&opts.RecordFile, "record", "", "File to record the simulation to for later replay")
+	flag.IntVar(&opts.KeyframeInterval, "keyframe-interval", 500, "Number of cycles between full keyframes in recordings, allowing faster seeking")
+	flag.StringVar(&opts.ReplayFile, "replay", "", "Recording file to play back instead of running a simulation")
+	flag.StringVar(&opts.MetricsFile, "metrics-out", "", "File to write metrics to every population update interval, as CSV (.csv) or JSON Lines (.jsonl)")
+	flag.StringVar(&opts.ChangesFile, "changes", "", "File of setting changes to apply at given cycles, as saved by -changes-out")
+	flag.StringVar(&opts.ChangesOutFile, "changes-out", "protozoa.changes.json", "File to save setting changes made while running with visualization to")
+	flag.StringVar(&opts.SweepFile, "sweep", "", "Sweep file in JSON format listing config settings to vary across headless runs")
+	flag.StringVar(&opts.SweepOutFile, "sweep-out", "sweep.csv", "File to write sweep results to, as CSV")
 
 	flag.Parse()
 
 	return &opts
 }
+
+// settingList collects the values of a flag that can be given more than once
+type settingList []string
+
+func (s *settingList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *settingList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Sweep describes a set of configs to run, each varying some settings of a
+// base config
+type Sweep struct {
+	// Seeds is the number of seeds each combination of settings is run with
+	Seeds int `json:"seeds"`
+	// Samples is the number of random combinations of settings to run, or 0
+	// to run every combination
+	Samples int `json:"samples"`
+	// Parameters maps config keys to the values to try for them, either as a
+	// list of values or a range like {"min": 0, "max": 1, "steps": 5}
+	Parameters map[string]json.RawMessage `json:"parameters"`
+}
+
+// SweepRange is an evenly-spaced range of values, including min and max
+type SweepRange struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Steps int     `json:"steps"`
+}
+
+// sweepParameter is a config key and every value to try for it
+type sweepParameter struct {
+	key        string
+	values     []interface{}
+	valueRange *SweepRange
+	// whole is true if the key is a whole number setting, whose range values
+	// are rounded
+	whole bool
+}
+
+// LoadSweep reads a sweep from a JSON file
+func LoadSweep(path string) (*Sweep, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sweep file: %w", err)
+	}
+	sweep := &Sweep{Seeds: 1}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(sweep); err != nil {
+		return nil, fmt.Errorf("failed to read sweep file %s: %w", path, err)
+	}
+	if sweep.Seeds < 1 {
+		return nil, fmt.Errorf("sweep file %s: seeds must be at least 1", path)
+	}
+	if len(sweep.Parameters) == 0 {
+		return nil, fmt.Errorf("sweep file %s: no parameters to sweep", path)
+	}
+	return sweep, nil
+}
+
+// Keys returns the config keys varied by the sweep, in alphabetical order
+func (s *Sweep) Keys() []string {
+	keys := make([]string, 0, len(s.Parameters))
+	for key := range s.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Combinations returns every combination of settings to run, as maps of config
+// keys to values. If Samples is set, that many combinations are chosen at
+// random instead, with range values drawn from anywhere within the range.
+func (s *Sweep) Combinations(r *rand.Rand) ([]map[string]interface{}, error) {
+	parameters := make([]sweepParameter, 0, len(s.Parameters))
+	for _, key := range s.Keys() {
+		parameter, err := parseSweepParameter(key, s.Parameters[key])
+		if err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	if s.Samples > 0 {
+		combinations := make([]map[string]interface{}, s.Samples)
+		for i := range combinations {
+			combinations[i] = make(map[string]interface{}, len(parameters))
+			for _, p := range parameters {
+				combinations[i][p.key] = p.sample(r)
+			}
+		}
+		return combinations, nil
+	}
+
+	combinations := []map[string]interface{}{{}}
+	for _, p := range parameters {
+		next := make([]map[string]interface{}, 0, len(combinations)*len(p.values))
+		for _, combination := range combinations {
+			for _, value := range p.values {
+				extended := make(map[string]interface{}, len(combination)+1)
+				for k, v := range combination {
+					extended[k] = v
+				}
+				extended[p.key] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+func parseSweepParameter(key string, raw json.RawMessage) (sweepParameter, error) {
+	parameter := sweepParameter{key: key}
+	if err := json.Unmarshal(raw, &parameter.values); err == nil {
+		if len(parameter.values) == 0 {
+			return parameter, fmt.Errorf("sweep parameter %s has no values", key)
+		}
+		return parameter, nil
+	}
+
+	valueRange := &SweepRange{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(valueRange); err != nil {
+		return parameter, fmt.Errorf("sweep parameter %s must be a list of values or a range with min, max and steps", key)
+	}
+	if valueRange.Steps < 1 {
+		return parameter, fmt.Errorf("sweep parameter %s must have at least 1 step", key)
+	}
+	parameter.valueRange = valueRange
+	if isWholeNumberSetting(key) {
+		parameter.whole = true
+		if math.Ceil(valueRange.Min) > math.Floor(valueRange.Max) {
+			return parameter, fmt.Errorf("sweep parameter %s is a whole number but its range has no whole numbers", key)
+		}
+	}
+	for i := 0; i < valueRange.Steps; i++ {
+		value := valueRange.Min
+		if valueRange.Steps > 1 {
+			value += (valueRange.Max - valueRange.Min) * float64(i) / float64(valueRange.Steps-1)
+		}
+		if parameter.whole {
+			// steps too close together to reach a new whole number are skipped
+			value = math.Round(value)
+			if n := len(parameter.values); n > 0 && parameter.values[n-1] == value {
+				continue
+			}
+		}
+		parameter.values = append(parameter.values, value)
+	}
+	return parameter, nil
+}
+
+// isWholeNumberSetting returns true if the setting with the given JSON name is
+// an int
+func isWholeNumberSetting(key string) bool {
+	globalsType := reflect.TypeOf(Globals{})
+	for i := 0; i < globalsType.NumField(); i++ {
+		field := globalsType.Field(i)
+		if strings.Split(field.Tag.Get("json"), ",")[0] == key {
+			return field.Type.Kind() == reflect.Int
+		}
+	}
+	return false
+}
+
+// sample returns a random value for the parameter. Whole number parameters
+// are sampled from the whole numbers within their range.
+func (p sweepParameter) sample(r *rand.Rand) interface{} {
+	if p.valueRange == nil {
+		return p.values[r.Intn(len(p.values))]
+	}
+	if p.whole {
+		low, high := math.Ceil(p.valueRange.Min), math.Floor(p.valueRange.Max)
+		return low + float64(r.Intn(int(high-low)+1))
+	}
+	return p.valueRange.Min + r.Float64()*(p.valueRange.Max-p.valueRange.Min)
+}
+
+// WithSettings returns a copy of the config with settings replaced by the
+// given values, keyed by their JSON names. Relative paths of files named by
+// the settings are resolved from the directory of the file they were read
+// from, as they are in settings files.
+func (g *Globals) WithSettings(path string, settings map[string]interface{}) (*Globals, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	combination := *g
+	v := &validator{}
+	if parsed, ok := parseSettings(path, data, v); ok {
+		decodeStrict(path, parsed, &combination, v)
+		resolvePaths(path, parsed, &combination)
+	}
+	return checkLoaded(&combination, v)
+}
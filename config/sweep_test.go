@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestSweepCombinations(t *testing.T) {
+	sweep := &Sweep{Seeds: 1, Parameters: map[string]json.RawMessage{
+		"initial_food":  json.RawMessage(`[10, 20, 30]`),
+		"max_organisms": json.RawMessage(`{"min": 100, "max": 200, "steps": 3}`),
+	}}
+	combinations, err := sweep.Combinations(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combinations) != 9 {
+		t.Fatalf("expected 9 combinations, found %d", len(combinations))
+	}
+
+	globals := GetDefaultGlobals()
+	last, err := globals.WithSettings("sweep.json", combinations[8])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.InitialFood != 30 || last.MaxOrganisms != 200 {
+		t.Errorf("expected last combination to set 30 food and 200 organisms, found %d and %d", last.InitialFood, last.MaxOrganisms)
+	}
+	if globals.MaxOrganisms == 200 {
+		t.Errorf("expected settings not to change the base config")
+	}
+
+	if _, err = globals.WithSettings("sweep.json", map[string]interface{}{"not_a_setting": 1}); err == nil {
+		t.Errorf("expected an error for an unknown setting")
+	}
+	if _, err = globals.WithSettings("sweep.json", map[string]interface{}{"max_organisms": 1.5}); err == nil {
+		t.Errorf("expected an error for a fractional integer setting")
+	}
+
+	// ranges of whole number settings only give whole numbers, whether
+	// sampled or stepped
+	for _, samples := range []int{0, 20} {
+		sweep = &Sweep{Seeds: 1, Samples: samples, Parameters: map[string]json.RawMessage{
+			"initial_organisms": json.RawMessage(`{"min": 50, "max": 60, "steps": 4}`),
+			"growth_factor":     json.RawMessage(`{"min": 0.2, "max": 0.3, "steps": 4}`),
+		}}
+		if combinations, err = sweep.Combinations(rand.New(rand.NewSource(1))); err != nil {
+			t.Fatal(err)
+		}
+		for _, combination := range combinations {
+			if _, err = globals.WithSettings("sweep.json", combination); err != nil {
+				t.Errorf("expected sampled and stepped ranges to give valid settings, found %v", err)
+			}
+		}
+	}
+	sweep = &Sweep{Seeds: 1, Parameters: map[string]json.RawMessage{
+		"initial_organisms": json.RawMessage(`{"min": 50.2, "max": 50.8, "steps": 2}`),
+	}}
+	if _, err = sweep.Combinations(rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("expected an error for a whole number range without whole numbers")
+	}
+}
+
+func TestSweepPathsAreFoundFromSweepFile(t *testing.T) {
+	globals := GetDefaultGlobals()
+	sweepPath := filepath.Join("..", "settings", "sweep.json")
+	swept, err := globals.WithSettings(sweepPath, map[string]interface{}{
+		"grid_units_wide": 100,
+		"grid_units_high": 60,
+		"wall_map":        "maps/corridors.txt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join("..", "settings", "maps", "corridors.txt"); swept.WallMap != expected {
+		t.Errorf("expected wall map %s, found %s", expected, swept.WallMap)
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Wave shapes
+const (
+	WaveSine     = "sine"
+	WaveTriangle = "triangle"
+	WaveSquare   = "square"
+)
+
+// Regime changes settings while it is active. A regime without a period is
+// active from its start cycle until its end cycle, or forever if it has no end.
+// A regime with a period is active for the first duration cycles of each
+// period counted from its start, or for the whole period if it has no
+// duration, until its end.
+type Regime struct {
+	Name     string             `json:"name"`
+	Start    int                `json:"start"`
+	End      int                `json:"end"`      // 0 for no end
+	Period   int                `json:"period"`   // 0 to not repeat
+	Duration int                `json:"duration"` // 0 for the whole period
+	Settings map[string]float64 `json:"settings"`
+	Waves    []Wave             `json:"waves"`
+}
+
+// Wave varies a setting between a min and max value while its regime is
+// active, repeating every period cycles from the regime's start
+type Wave struct {
+	Key    string  `json:"key"`
+	Shape  string  `json:"shape"` // "sine" (the default), "triangle" or "square"
+	Period int     `json:"period"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Phase  float64 `json:"phase"` // fraction of a period to shift the wave by
+}
+
+// IsActive returns true if the regime is active on the given cycle
+func (r *Regime) IsActive(cycle int) bool {
+	if cycle < r.Start || (r.End > 0 && cycle >= r.End) {
+		return false
+	}
+	if r.Period <= 0 || r.Duration <= 0 {
+		return true
+	}
+	return (cycle-r.Start)%r.Period < r.Duration
+}
+
+// Value returns the value of the wave a given number of cycles after it began
+func (w *Wave) Value(cycles int) float64 {
+	position := math.Mod(float64(cycles)/float64(w.Period)+w.Phase, 1)
+	if position < 0 {
+		position++
+	}
+	var level float64 // from 0 to 1
+	switch w.Shape {
+	case WaveSquare:
+		if position < 0.5 {
+			level = 1
+		}
+	case WaveTriangle:
+		level = 1 - math.Abs(2*position-1)
+	default:
+		level = (1 + math.Sin(2*math.Pi*position)) / 2
+	}
+	return w.Min + level*(w.Max-w.Min)
+}
+
+// TimelineKeys returns every setting changed by the timeline, in alphabetical
+// order
+func (g *Globals) TimelineKeys() []string {
+	keySet := make(map[string]bool)
+	for _, regime := range g.Timeline {
+		for key := range regime.Settings {
+			keySet[key] = true
+		}
+		for _, wave := range regime.Waves {
+			keySet[wave.Key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TimelineValues returns the value of every setting changed by the timeline
+// on the given cycle, along with the names of the regimes active on it.
+// Settings that no active regime changes take their base value. Where active
+// regimes change the same setting, the one listed last wins.
+func (g *Globals) TimelineValues(cycle int, base map[string]float64) (map[string]float64, []string) {
+	values := make(map[string]float64, len(base))
+	for key, value := range base {
+		values[key] = value
+	}
+	var active []string
+	for i := range g.Timeline {
+		regime := &g.Timeline[i]
+		if !regime.IsActive(cycle) {
+			continue
+		}
+		active = append(active, regime.Name)
+		for key, value := range regime.Settings {
+			values[key] = value
+		}
+		for _, wave := range regime.Waves {
+			values[wave.Key] = wave.Value(cycle - regime.Start)
+		}
+	}
+	return values, active
+}
+
+func (g *Globals) validateTimeline(v *validator) {
+	// every value the timeline gives a setting must be within its tunable
+	// range, which is all that is checked when the timeline changes it
+	checkValue := func(name, key string, value float64) {
+		tunable, ok := findTunable(key)
+		if !ok {
+			v.check(false, "timeline regime %q: %q can't be changed while a simulation runs", name, key)
+			return
+		}
+		v.check(value >= tunable.Min && value <= tunable.Max,
+			"timeline regime %q: %s must be between %g and %g, found %g", name, key, tunable.Min, tunable.Max, value)
+	}
+	for i, regime := range g.Timeline {
+		name := regime.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		v.check(regime.Start >= 0, "timeline regime %q: start must not be negative", name)
+		v.check(regime.End == 0 || regime.End > regime.Start, "timeline regime %q: end must be after start", name)
+		v.check(regime.Period >= 0, "timeline regime %q: period must not be negative", name)
+		v.check(regime.Duration >= 0 && (regime.Period == 0 || regime.Duration <= regime.Period),
+			"timeline regime %q: duration must be between 0 and the period", name)
+		v.check(len(regime.Settings) > 0 || len(regime.Waves) > 0, "timeline regime %q: changes no settings", name)
+		for key, value := range regime.Settings {
+			checkValue(name, key, value)
+		}
+		for _, wave := range regime.Waves {
+			checkValue(name, wave.Key, wave.Min)
+			checkValue(name, wave.Key, wave.Max)
+			v.check(wave.Period > 0, "timeline regime %q: wave for %s must have a period greater than 0", name, wave.Key)
+			v.check(wave.Shape == "" || wave.Shape == WaveSine || wave.Shape == WaveTriangle || wave.Shape == WaveSquare,
+				"timeline regime %q: wave shape %q must be sine, triangle or square", name, wave.Shape)
+			v.check(wave.Min <= wave.Max, "timeline regime %q: wave for %s must have a min no greater than its max", name, wave.Key)
+		}
+	}
+	for _, key := range g.TimelineKeys() {
+		tunable, ok := findTunable(key)
+		value, _ := g.Setting(key)
+		v.check(!ok || (value >= tunable.Min && value <= tunable.Max),
+			"%s must be between %g and %g while the timeline changes it, found %g", key, tunable.Min, tunable.Max, value)
+	}
+}
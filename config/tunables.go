@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Tunable is a setting that is safe to change while a simulation runs, along
+// with the range of values offered for it
+type Tunable struct {
+	Key  string
+	Name string
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// Tunables lists the settings that can be changed while a simulation runs.
+// Settings used to build the world, like its size and walls, are left out.
+var Tunables = []Tunable{
+	{"chance_to_add_food_item", "FOOD CHANCE", 0, 1, 0.01},
+	{"ph_diffuse_factor", "PH DIFFUSION", 0, 0.2, 0.005},
+	{"max_organism_ph_growth_effect", "PH GROWTH EFFECT", 0, 0.2, 0.005},
+	{"growth_factor", "GROWTH FACTOR", 0, 1, 0.05},
+	{"max_organisms", "MAX ORGANISMS", 0, 100000, 1000},
+	{"health_change_from_chemosynthesis", "CHEMOSYNTHESIS", 0, 0.2, 0.005},
+	{"min_light", "MIN LIGHT", 0, 2, 0.05},
+	{"max_light", "MAX LIGHT", 0, 2, 0.05},
+	{"night_light", "NIGHT LIGHT", 0, 1, 0.05},
+	{"max_shade", "MAX SHADE", 0, 1, 0.05},
+	{"health_change_inflicted_by_attack", "ATTACK DAMAGE", -5, 0, 0.1},
+	{"health_change_from_attacking", "ATTACK COST", -1, 0, 0.01},
+	{"health_change_from_moving", "MOVE COST", -1, 0, 0.01},
+	{"health_change_from_turning", "TURN COST", -1, 0, 0.01},
+	{"health_change_from_eating_attempt", "EAT COST", -1, 0, 0.01},
+	{"health_change_from_feeding", "FEED COST", -1, 0, 0.01},
+	{"health_change_per_decision_tree_node", "TREE NODE COST", -0.01, 0, 0.0005},
+	{"health_change_per_unhealthy_ph", "UNHEALTHY PH", -2, 0, 0.05},
+}
+
+// SettingChange is a change to a numeric setting made at the beginning of a
+// given cycle while a simulation runs
+type SettingChange struct {
+	Cycle int     `json:"cycle"`
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+}
+
+// LoadSettingChanges reads a JSON list of setting changes, sorted by cycle
+func LoadSettingChanges(path string) ([]SettingChange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read setting changes: %w", err)
+	}
+	var changes []SettingChange
+	if err = json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to read setting changes from %s: %w", path, err)
+	}
+	defaults := GetDefaultGlobals()
+	for _, change := range changes {
+		if _, ok := defaults.Setting(change.Key); !ok {
+			return nil, fmt.Errorf("%s: %q is not a numeric setting", path, change.Key)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Cycle < changes[j].Cycle
+	})
+	return changes, nil
+}
+
+// SaveSettingChanges writes a JSON list of setting changes that can be loaded
+// again with LoadSettingChanges
+func SaveSettingChanges(path string, changes []SettingChange) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Setting returns the value of a numeric setting by its JSON name, and false
+// if there is no such setting
+func (g *Globals) Setting(key string) (float64, bool) {
+	field, ok := g.settingField(key)
+	if !ok {
+		return 0, false
+	}
+	if field.Kind() == reflect.Int {
+		return float64(field.Int()), true
+	}
+	return field.Float(), true
+}
+
+// SetSetting changes a numeric setting by its JSON name. Whole number
+// settings are rounded to the nearest whole number. The change is undone if
+// it leaves the config invalid.
+func (g *Globals) SetSetting(key string, value float64) error {
+	field, ok := g.settingField(key)
+	if !ok {
+		return fmt.Errorf("%q is not a numeric setting", key)
+	}
+	previous := *g
+	setField(field, value)
+	if err := g.Validate(); err != nil {
+		*g = previous
+		return err
+	}
+	return nil
+}
+
+// SetTunable changes a tunable setting by its JSON name, checking only that
+// the value is within the tunable's range instead of validating the whole
+// config, so it is cheap enough to call on every cycle. Whole number settings
+// are rounded to the nearest whole number.
+func (g *Globals) SetTunable(key string, value float64) error {
+	tunable, ok := findTunable(key)
+	if !ok {
+		return fmt.Errorf("%q can't be changed while a simulation runs", key)
+	}
+	if value < tunable.Min || value > tunable.Max {
+		return fmt.Errorf("%s must be between %g and %g, found %g", key, tunable.Min, tunable.Max, value)
+	}
+	field, _ := g.settingField(key)
+	setField(field, value)
+	return nil
+}
+
+// findTunable returns the tunable setting with the given JSON name
+func findTunable(key string) (Tunable, bool) {
+	for _, tunable := range Tunables {
+		if tunable.Key == key {
+			return tunable, true
+		}
+	}
+	return Tunable{}, false
+}
+
+// setField sets an int or float64 field, rounding values for int fields
+func setField(field reflect.Value, value float64) {
+	if field.Kind() == reflect.Int {
+		field.SetInt(int64(math.Round(value)))
+	} else {
+		field.SetFloat(value)
+	}
+}
+
+// settingField returns the int or float64 field with the given JSON name
+func (g *Globals) settingField(key string) (reflect.Value, bool) {
+	field, ok := g.field(key)
+	if !ok {
+		return field, false
+	}
+	kind := field.Kind()
+	return field, kind == reflect.Int || kind == reflect.Float64
+}
+
+// field returns the field with the given JSON name
+func (g *Globals) field(key string) (reflect.Value, bool) {
+	value := reflect.ValueOf(g).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == key {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
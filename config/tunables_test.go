@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestSetSetting(t *testing.T) {
+	g := GetDefaultGlobals()
+	if err := g.SetSetting("max_organisms", 1234.6); err != nil || g.MaxOrganisms != 1235 {
+		t.Errorf("expected max_organisms to be rounded to 1235, found %d (%v)", g.MaxOrganisms, err)
+	}
+	if err := g.SetSetting("chance_to_add_food_item", 2); err == nil {
+		t.Errorf("expected an error for a chance above 1")
+	}
+	if g.ChanceToAddFoodItem != GetDefaultGlobals().ChanceToAddFoodItem {
+		t.Errorf("expected an invalid change to be undone, found %f", g.ChanceToAddFoodItem)
+	}
+	if err := g.SetSetting("use_pools", 1); err == nil {
+		t.Errorf("expected an error for a setting that isn't numeric")
+	}
+	for _, tunable := range Tunables {
+		if _, ok := g.Setting(tunable.Key); !ok {
+			t.Errorf("tunable %s is not a numeric setting", tunable.Key)
+		}
+	}
+}
+
+func TestSetTunable(t *testing.T) {
+	g := GetDefaultGlobals()
+	if err := g.SetTunable("max_organisms", 1234.6); err != nil || g.MaxOrganisms != 1235 {
+		t.Errorf("expected max_organisms to be rounded to 1235, found %d (%v)", g.MaxOrganisms, err)
+	}
+	if err := g.SetTunable("chance_to_add_food_item", 2); err == nil {
+		t.Errorf("expected an error for a value outside the tunable range")
+	}
+	if g.ChanceToAddFoodItem != GetDefaultGlobals().ChanceToAddFoodItem {
+		t.Errorf("expected an out of range value not to be set, found %f", g.ChanceToAddFoodItem)
+	}
+	if err := g.SetTunable("world_width", 100); err == nil {
+		t.Errorf("expected an error for a setting that isn't tunable")
+	}
+}
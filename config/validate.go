@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid config:")
+	for _, problem := range e.Problems {
+		sb.WriteString("\n  - ")
+		sb.WriteString(problem)
+	}
+	return sb.String()
+}
+
+// validator collects problems found while checking a config
+type validator struct {
+	problems []string
+}
+
+// check records a problem if a condition does not hold
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// add records a problem found in the settings from a given source, such as a
+// file name
+func (v *validator) add(source string, format string, args ...interface{}) {
+	problem := fmt.Sprintf(format, args...)
+	if source != "" {
+		problem = source + ": " + problem
+	}
+	v.problems = append(v.problems, problem)
+}
+
+func (v *validator) positive(name string, value int) {
+	v.check(value > 0, "%s must be greater than 0, found %d", name, value)
+}
+
+func (v *validator) nonNegative(name string, value int) {
+	v.check(value >= 0, "%s must not be negative, found %d", name, value)
+}
+
+func (v *validator) fraction(name string, value float64) {
+	v.check(value >= 0 && value <= 1, "%s must be between 0 and 1, found %g", name, value)
+}
+
+func (v *validator) ordered(minName string, min float64, maxName string, max float64) {
+	v.check(min <= max, "%s (%g) must not be greater than %s (%g)", minName, min, maxName, max)
+}
+
+// err returns a ValidationError listing the problems found, or nil if there
+// were none
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// Validate checks that the config's settings are usable and consistent with
+// each other, returning a ValidationError listing every problem found
+func (g *Globals) Validate() error {
+	v := &validator{}
+	g.validate(v)
+	return v.err()
+}
+
+func (g *Globals) validate(v *validator) {
+	v.positive("grid_units_wide", g.GridUnitsWide)
+	v.positive("grid_units_high", g.GridUnitsHigh)
+	v.nonNegative("grid_unit_size", g.GridUnitSize)
+	v.nonNegative("screen_width", g.ScreenWidth)
+	v.nonNegative("screen_height", g.ScreenHeight)
+	if g.ScreenWidth > 0 {
+		v.check(g.ScreenWidth >= PanelWidth+g.GridWidth(),
+			"screen_width (%d) must fit the panel (%d) and grid (%d units of %d pixels), or be 0 to fit automatically",
+			g.ScreenWidth, PanelWidth, g.GridUnitsWide, g.UnitSize())
+	}
+	if g.ScreenHeight > 0 {
+		v.check(g.ScreenHeight >= g.GridHeight() && g.ScreenHeight >= MinWindowHeight,
+			"screen_height (%d) must be at least %d and fit the grid (%d units of %d pixels), or be 0 to fit automatically",
+			g.ScreenHeight, MinWindowHeight, g.GridUnitsHigh, g.UnitSize())
+	}
+	v.positive("population_update_interval", g.PopulationUpdateInterval)
+
+	v.nonNegative("initial_organisms", g.InitialOrganisms)
+	v.nonNegative("initial_food", g.InitialFood)
+	v.fraction("chance_to_add_food_item", g.ChanceToAddFoodItem)
+	v.nonNegative("min_food_value", g.MinFoodValue)
+	v.positive("max_food_value", g.MaxFoodValue)
+	v.ordered("min_food_value", float64(g.MinFoodValue), "max_food_value", float64(g.MaxFoodValue))
+	v.ordered("min_ph", g.MinPh, "max_ph", g.MaxPh)
+	v.ordered("min_initial_ph", g.MinInitialPh, "max_initial_ph", g.MaxInitialPh)
+	v.check(g.MinInitialPh >= g.MinPh && g.MaxInitialPh <= g.MaxPh,
+		"min_initial_ph and max_initial_ph (%g to %g) must be within min_ph and max_ph (%g to %g)",
+		g.MinInitialPh, g.MaxInitialPh, g.MinPh, g.MaxPh)
+	validateLandscape(v, "initial_ph_landscape", g.InitialPhLandscape)
+	g.validateFields(v)
+	g.validateVents(v)
+	validateLandscape(v, "light_landscape", g.LightLandscape)
+	v.check(g.MinLight >= 0, "min_light must not be negative, found %g", g.MinLight)
+	v.ordered("min_light", g.MinLight, "max_light", g.MaxLight)
+	v.nonNegative("day_length", g.DayLength)
+	v.fraction("night_light", g.NightLight)
+	v.fraction("max_shade", g.MaxShade)
+	v.fraction("ph_diffuse_factor", g.PhDiffuseFactor)
+	v.check(g.PhIncrementToDisplay > 0, "ph_increment_to_display must be greater than 0, found %g", g.PhIncrementToDisplay)
+	if g.UsePools {
+		v.positive("pool_width", g.PoolWidth)
+		v.positive("pool_height", g.PoolHeight)
+		v.check(g.PoolWidth <= g.GridUnitsWide && g.PoolHeight <= g.GridUnitsHigh,
+			"pool_width and pool_height (%d x %d) must fit within the grid (%d x %d)",
+			g.PoolWidth, g.PoolHeight, g.GridUnitsWide, g.GridUnitsHigh)
+	}
+
+	v.positive("max_cycles_between_spawns", g.MaxCyclesBetweenSpawns)
+	v.fraction("max_spawn_health_percent", g.MaxSpawnHealthPercent)
+	v.nonNegative("min_organisms", g.MinOrganisms)
+	v.positive("max_organisms", g.MaxOrganisms)
+	v.ordered("min_organisms", float64(g.MinOrganisms), "max_organisms", float64(g.MaxOrganisms))
+	v.check(g.MinimumMaxSize > 0, "minimum_max_size must be greater than 0, found %g", g.MinimumMaxSize)
+	v.ordered("minimum_max_size", g.MinimumMaxSize, "maximum_max_size", g.MaximumMaxSize)
+	v.nonNegative("initial_organism_decision_tree_mutations", g.InitialDecisionTreeMutations)
+	v.fraction("min_chance_to_mutate_decision_tree", g.MinChanceToMutateDecisionTree)
+	v.fraction("max_chance_to_mutate_decision_tree", g.MaxChanceToMutateDecisionTree)
+	v.ordered("min_chance_to_mutate_decision_tree", g.MinChanceToMutateDecisionTree,
+		"max_chance_to_mutate_decision_tree", g.MaxChanceToMutateDecisionTree)
+	v.positive("max_decision_tree_size", g.MaxDecisionTreeSize)
+	v.ordered("min_ideal_ph", g.MinIdealPh, "max_ideal_ph", g.MaxIdealPh)
+	v.ordered("min_ph_tolerance", g.MinPhTolerance, "max_ph_tolerance", g.MaxPhTolerance)
+	v.ordered("min_change_to_ph", g.MinChangeToPh, "max_change_to_ph", g.MaxChangeToPh)
+
+	v.nonNegative("end_max_cycles", g.EndMaxCycles)
+	v.nonNegative("end_population_above", g.EndPopulationAbove)
+	v.nonNegative("end_population_below", g.EndPopulationBelow)
+	v.positive("end_population_cycles", g.EndPopulationCycles)
+	v.fraction("end_lineage_dominance", g.EndLineageDominance)
+	v.ordered("end_min_average_ph", g.EndMinAveragePh, "end_max_average_ph", g.EndMaxAveragePh)
+	g.validateTimeline(v)
+}
+
+// removedSettings explains what replaced settings that are no longer used
+var removedSettings = map[string]string{
+	"grid_width":  "it is grid_units_wide * grid_unit_size",
+	"grid_height": "it is grid_units_high * grid_unit_size",
+}
+
+// parseSettings reads a JSON object of settings from a given source, returning
+// false if it could not be read
+func parseSettings(source string, data []byte, v *validator) (map[string]json.RawMessage, bool) {
+	settings := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		v.add(source, "%s", describeJSONError(data, err))
+		return nil, false
+	}
+	return settings, true
+}
+
+// decodeStrict applies settings from a given source to a config, reporting
+// every unknown setting, including unknown fields nested within settings, and
+// every setting of the wrong type
+func decodeStrict(source string, settings map[string]json.RawMessage, g *Globals, v *validator) {
+	globalsType := reflect.TypeOf(*g)
+	known := jsonKeys(globalsType)
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if hint, ok := removedSettings[key]; ok {
+			v.add(source, "%s is no longer a setting: %s", key, hint)
+			continue
+		}
+		if !known[key] {
+			v.add(source, "unknown setting %q", key)
+			continue
+		}
+		field, _ := jsonField(globalsType, key)
+		unknown := unknownFields(key, settings[key], field.Type)
+		for _, path := range unknown {
+			v.add(source, "unknown setting %q", path)
+		}
+		// decode settings one at a time so every bad value is reported
+		single, _ := json.Marshal(map[string]json.RawMessage{key: settings[key]})
+		decoder := json.NewDecoder(bytes.NewReader(single))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(g); err != nil && !(len(unknown) > 0 && isUnknownFieldError(err)) {
+			v.add(source, "%s", describeSettingError(key, err))
+		}
+	}
+}
+
+// unknownFields returns the path of every object key within a setting's value
+// that doesn't match a field of the type it is decoded into, such as
+// "setting[0].field"
+func unknownFields(path string, raw json.RawMessage, t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			return nil
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field, ok := jsonField(t, key)
+			if !ok {
+				unknown = append(unknown, path+"."+key)
+				continue
+			}
+			unknown = append(unknown, unknownFields(path+"."+key, object[key], field.Type)...)
+		}
+	case reflect.Slice, reflect.Array:
+		var list []json.RawMessage
+		if json.Unmarshal(raw, &list) != nil {
+			return nil
+		}
+		for i, item := range list {
+			unknown = append(unknown, unknownFields(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())...)
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(raw, &object) != nil {
+			return nil
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			unknown = append(unknown, unknownFields(path+"."+key, object[key], t.Elem())...)
+		}
+	}
+	return unknown
+}
+
+// isUnknownFieldError returns true if a decoding error was caused by an
+// unknown field, which encoding/json doesn't give a distinct error type
+func isUnknownFieldError(err error) bool {
+	return strings.HasPrefix(err.Error(), "json: unknown field")
+}
+
+// jsonField returns the field of a struct type with the given JSON name,
+// matched without regard to case like encoding/json does
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if fieldName == "-" || !field.IsExported() {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if strings.EqualFold(fieldName, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonKeys returns the set of JSON names of a struct type's fields
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// listIndex matches the list indices in the field paths of decoding errors
+var listIndex = regexp.MustCompile(`\.(\d+)`)
+
+func describeSettingError(key string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Sprintf("%s: %v", key, err)
+	}
+	if typeErr.Field != "" {
+		// name the nested field for settings holding objects or lists,
+		// writing any list indices the same way as unknownFields does
+		key = listIndex.ReplaceAllString(typeErr.Field, "[$1]")
+	}
+	expected := typeErr.Type.String()
+	switch typeErr.Type.Kind() {
+	case reflect.Int:
+		expected = "a whole number"
+	case reflect.Float64:
+		expected = "a number"
+	case reflect.Bool:
+		expected = "true or false"
+	}
+	return fmt.Sprintf("%s must be %s, found %s", key, expected, typeErr.Value)
+}
+
+func describeJSONError(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n"))
+		return fmt.Sprintf("not valid JSON on line %d: %v", line, err)
+	}
+	return fmt.Sprintf("not a JSON object of settings: %v", err)
+}
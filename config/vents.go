@@ -0,0 +1,34 @@
+package config
+
+// Vent is a persistent source or sink of pH, or of another field, that changes
+// the values around it every cycle. Positive strengths raise pH, like an
+// alkaline vent, and negative strengths lower it, like an acidic vent. A vent
+// that names a field changes that field instead, such as a hot vent raising
+// temperature.
+type Vent struct {
+	Field    string  `json:"field"` // name of the field changed, "" for pH
+	X        int     `json:"x"`
+	Y        int     `json:"y"`
+	Random   bool    `json:"random"`   // place at random open locations instead of at x, y
+	Count    int     `json:"count"`    // number of random vents to place, 0 for 1
+	Strength float64 `json:"strength"` // change per cycle at the vent
+	Radius   int     `json:"radius"`   // distance the change reaches, fading with distance
+}
+
+// validateVents checks that every vent fits on the grid
+func (g *Globals) validateVents(v *validator) {
+	for i, vent := range g.Vents {
+		if _, ok := g.FieldIndex(vent.Field); vent.Field != "" && !ok {
+			v.add("", "vents[%d]: unknown field %q", i, vent.Field)
+		}
+		v.check(vent.Radius >= 0, "vents[%d]: radius must not be negative, found %d", i, vent.Radius)
+		v.check(vent.Radius < g.GridUnitsWide && vent.Radius < g.GridUnitsHigh,
+			"vents[%d]: radius (%d) must be smaller than the grid (%d x %d)", i, vent.Radius, g.GridUnitsWide, g.GridUnitsHigh)
+		if vent.Random {
+			v.check(vent.Count >= 0, "vents[%d]: count must not be negative, found %d", i, vent.Count)
+			continue
+		}
+		v.check(vent.X >= 0 && vent.Y >= 0 && vent.X < g.GridUnitsWide && vent.Y < g.GridUnitsHigh,
+			"vents[%d]: position (%d, %d) must be on the grid (%d x %d)", i, vent.X, vent.Y, g.GridUnitsWide, g.GridUnitsHigh)
+	}
+}
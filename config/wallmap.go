@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WallMap is a layout of walls with one cell per grid unit
+type WallMap struct {
+	Width, Height int
+	walls         []bool
+}
+
+// IsWallAt returns true if the map has a wall at the given coordinates
+func (m *WallMap) IsWallAt(x, y int) bool {
+	return m.walls[y*m.Width+x]
+}
+
+// LoadWallMap reads a wall layout from a PNG image, where dark pixels are walls
+// and light or transparent pixels are open, or from a text file, where '#'
+// characters are walls and '.' or ' ' characters are open. Lines shorter than
+// the longest line are open to their end.
+func LoadWallMap(path string) (*WallMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wall map: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		return parseWallImage(path, data)
+	}
+	return parseWallText(path, data)
+}
+
+func parseWallImage(path string, data []byte) (*WallMap, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wall map %s: %w", path, err)
+	}
+	bounds := img.Bounds()
+	m := &WallMap{Width: bounds.Dx(), Height: bounds.Dy()}
+	m.walls = make([]bool, m.Width*m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			pixel := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			_, _, _, alpha := pixel.RGBA()
+			gray := color.GrayModel.Convert(pixel).(color.Gray)
+			m.walls[y*m.Width+x] = alpha >= 0x8000 && gray.Y < 0x80
+		}
+	}
+	return m, nil
+}
+
+func parseWallText(path string, data []byte) (*WallMap, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	// ignore blank lines at the end of the file
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	m := &WallMap{Height: len(lines)}
+	for _, line := range lines {
+		if len(line) > m.Width {
+			m.Width = len(line)
+		}
+	}
+	m.walls = make([]bool, m.Width*m.Height)
+	for y, line := range lines {
+		for x, char := range []byte(line) {
+			switch char {
+			case '#':
+				m.walls[y*m.Width+x] = true
+			case '.', ' ':
+			default:
+				return nil, fmt.Errorf("wall map %s: unexpected %q on line %d, column %d (use '#' for walls and '.' for open space)", path, char, y+1, x+1)
+			}
+		}
+	}
+	return m, nil
+}
+
+// validateWallMap checks that the wall map, if any, can be read and matches
+// the size of the grid
+func (g *Globals) validateWallMap(v *validator) {
+	if g.WallMap == "" {
+		return
+	}
+	m, err := LoadWallMap(g.WallMap)
+	if err != nil {
+		v.add("wall_map", "%v", err)
+		return
+	}
+	v.check(m.Width == g.GridUnitsWide && m.Height == g.GridUnitsHigh,
+		"wall_map %s is %d x %d, but the grid is %d x %d (grid_units_wide x grid_units_high)",
+		g.WallMap, m.Width, m.Height, g.GridUnitsWide, g.GridUnitsHigh)
+}
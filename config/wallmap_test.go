@@ -0,0 +1,49 @@
+package config
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadWallMap(t *testing.T) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "walls.txt")
+	writeSettings(t, textPath, "#..#\n....\n##\n\n")
+	m, err := LoadWallMap(textPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Width != 4 || m.Height != 3 || !m.IsWallAt(3, 0) || m.IsWallAt(1, 1) || !m.IsWallAt(1, 2) || m.IsWallAt(3, 2) {
+		t.Errorf("text wall map was read incorrectly: %+v", m)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 0, color.Black)
+	img.Set(2, 1, color.White)
+	imagePath := filepath.Join(dir, "walls.png")
+	file, _ := os.Create(imagePath)
+	if err = png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if m, err = LoadWallMap(imagePath); err != nil {
+		t.Fatal(err)
+	}
+	if m.Width != 3 || m.Height != 2 || !m.IsWallAt(1, 0) || m.IsWallAt(0, 0) || m.IsWallAt(2, 1) {
+		t.Errorf("image wall map was read incorrectly: %+v", m)
+	}
+
+	_, err = LoadGlobals(strings.NewReader(`{"wall_map": "` + filepath.ToSlash(imagePath) + `"}`))
+	if err == nil || !strings.Contains(err.Error(), "is 3 x 2, but the grid is 200 x 160") {
+		t.Errorf("expected a size mismatch to be reported, found %v", err)
+	}
+	writeSettings(t, textPath, "#.x\n")
+	if _, err = LoadWallMap(textPath); err == nil || !strings.Contains(err.Error(), "line 1, column 3") {
+		t.Errorf("expected an unexpected character to be reported, found %v", err)
+	}
+}
@@ -31,6 +31,16 @@ const (
 	IsHealthyPhHere
 	IsHealthierPhAhead
 	//IsRandomFiftyPercent
+	// Actions and conditions for grids with eight directions. These come last
+	// so that serialized trees from four-direction grids keep their values.
+	ActTurnHalfLeft Action = iota
+	ActTurnHalfRight
+	IsFoodAheadLeft Condition = iota
+	IsFoodAheadRight
+	IsOrganismAheadLeft
+	IsRelatedOrganismAheadLeft
+	IsOrganismAheadRight
+	IsRelatedOrganismAheadRight
 )
 
 // Define slices
@@ -62,6 +72,22 @@ var (
 		IsHealthierPhAhead,
 		//IsRandomFiftyPercent,
 	}
+	// DiagonalActions are the actions added to Actions on grids with eight
+	// directions
+	DiagonalActions = [...]Action{
+		ActTurnHalfLeft,
+		ActTurnHalfRight,
+	}
+	// DiagonalConditions are the conditions added to Conditions on grids with
+	// eight directions
+	DiagonalConditions = [...]Condition{
+		IsFoodAheadLeft,
+		IsFoodAheadRight,
+		IsOrganismAheadLeft,
+		IsRelatedOrganismAheadLeft,
+		IsOrganismAheadRight,
+		IsRelatedOrganismAheadRight,
+	}
 	Map = map[interface{}]string{
 		ActAttack:                 "Attack",
 		ActFeed:                   "Feed",
@@ -86,5 +112,16 @@ var (
 		IsHealthyPhHere:           "IsHealthyPhHere",
 		IsHealthierPhAhead:        "IsHealthierPhAhead",
 		//IsRandomFiftyPercent:      "IsRandomFiftyPercent",
+		ActTurnHalfLeft:             "Turn Half Left",
+		ActTurnHalfRight:            "Turn Half Right",
+		IsFoodAheadLeft:             "If Food Ahead Left",
+		IsFoodAheadRight:            "If Food Ahead Right",
+		IsOrganismAheadLeft:         "If Organism Ahead Left",
+		IsRelatedOrganismAheadLeft:  "If Related Organism Ahead Left",
+		IsOrganismAheadRight:        "If Organism Ahead Right",
+		IsRelatedOrganismAheadRight: "If Related Organism Ahead Right",
 	}
+
+	eightDirectionActions    = append(Actions[:], DiagonalActions[:]...)
+	eightDirectionConditions = append(Conditions[:], DiagonalConditions[:]...)
 )
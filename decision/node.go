@@ -3,7 +3,7 @@ package decision
 import (
 	"bytes"
 	"fmt"
-	"sync"
+	"strconv"
 )
 
 // Node contains an Action or Condition NodeType and (if a Condition), child
@@ -13,8 +13,6 @@ type Node struct {
 	InDecisionTree, UsedLastCycle bool
 	YesNode, NoNode               *Node
 	size                          int
-
-	mutex sync.Mutex
 }
 
 // NodeFromAction creates a simple Node object from an Action type
@@ -125,3 +123,32 @@ func (n *Node) print(indent string, first, last bool) string {
 	}
 	return toPrint
 }
+
+// nodeFromString parses the first full Node structure found in a serialized
+// string and returns it along with the remaining unparsed string
+func nodeFromString(serialized string) (*Node, string, error) {
+	if len(serialized) < 2 {
+		return nil, serialized, fmt.Errorf("incomplete decision tree node %q", serialized)
+	}
+	value, err := strconv.Atoi(serialized[:2])
+	if err != nil {
+		return nil, serialized, fmt.Errorf("invalid decision tree node %q: %w", serialized[:2], err)
+	}
+	nodeType, ok := nodeTypeFromInt(value)
+	if !ok {
+		return nil, serialized, fmt.Errorf("unknown decision tree node type %d", value)
+	}
+
+	node := &Node{NodeType: nodeType, size: 1}
+	remaining := serialized[2:]
+	if node.IsAction() {
+		return node, remaining, nil
+	}
+	if node.YesNode, remaining, err = nodeFromString(remaining); err != nil {
+		return nil, remaining, err
+	}
+	if node.NoNode, remaining, err = nodeFromString(remaining); err != nil {
+		return nil, remaining, err
+	}
+	return node, remaining, nil
+}
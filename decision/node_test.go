@@ -19,3 +19,40 @@ func TestUpdateNodeIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeFromString(t *testing.T) {
+	testCases := []struct {
+		serialized string
+		size       int
+		expectErr  bool
+	}{
+		{"00", 1, false},
+		{"080002", 3, false},
+		{"0809040102", 5, false},
+		{"242223", 3, false},
+		{"08", 0, true},
+		{"0800", 0, true},
+		{"000", 0, true},
+		{"99", 0, true},
+	}
+
+	for index, testCase := range testCases {
+		tree, err := TreeFromString(testCase.serialized)
+		if testCase.expectErr {
+			if err == nil {
+				t.Errorf("tree %d (%s) parsed without error, expected failure\n", index, testCase.serialized)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("tree %d (%s) failed to parse: %v\n", index, testCase.serialized, err)
+			continue
+		}
+		if actual := tree.Serialize(); actual != testCase.serialized {
+			t.Errorf("tree %d was serialized as %s, expected %s\n", index, actual, testCase.serialized)
+		}
+		if tree.Size() != testCase.size {
+			t.Errorf("tree %d had size %d, expected %d\n", index, tree.Size(), testCase.size)
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package decision
 
 import (
+	"fmt"
 	"math/rand"
-
-	"github.com/Zebbeni/protozoa/config"
 )
 
 // Tree is a Node with info to track its success as a top-level decision tree
@@ -31,47 +30,47 @@ func (t *Tree) CopyTree() *Tree {
 	return tree
 }
 
-// MutateTree copies a root Tree, makes changes to the full tree, and returns
-func MutateTree(original *Tree) *Tree {
+// MutateTree copies a root Tree, makes changes to the full tree, and returns.
+// The mutated tree will not grow beyond maxTreeSize nodes, and only uses the
+// diagonal actions and conditions if eightDirections is true.
+func MutateTree(original *Tree, maxTreeSize int, eightDirections bool, r *rand.Rand) *Tree {
 	tree := original.CopyTree()
-	tree.mutate()
+	tree.mutate(maxTreeSize, eightDirections, r)
 	return tree
 }
 
 // mutate randomly mutates a single node of a tree. This function
 // should only be called on root tree nodes because it uses the tree size.
-func (t *Tree) mutate() {
+func (t *Tree) mutate(maxTreeSize int, eightDirections bool, r *rand.Rand) {
 	// pick a random t anywhere in the decision tree
 	allSubNodes := t.getNodes()
-	node := allSubNodes[rand.Intn(len(allSubNodes))]
-
-	maxTreeSize := config.MaxDecisionTreeSize()
+	node := allSubNodes[r.Intn(len(allSubNodes))]
 
 	if node.IsAction() {
-		if rand.Intn(2) == 0 && t.size < maxTreeSize-1 {
+		if r.Intn(2) == 0 && t.size < maxTreeSize-1 {
 			// convert action to condition + 2 actions
 			originalAction := node.NodeType.(Action)
-			node.NodeType = GetRandomCondition()
-			if rand.Intn(2) == 0 {
-				node.YesNode = NodeFromAction(GetRandomAction())
+			node.NodeType = GetRandomCondition(r, eightDirections)
+			if r.Intn(2) == 0 {
+				node.YesNode = NodeFromAction(GetRandomAction(r, eightDirections))
 				node.NoNode = NodeFromAction(originalAction)
 			} else {
 				node.YesNode = NodeFromAction(originalAction)
-				node.NoNode = NodeFromAction(GetRandomAction())
+				node.NoNode = NodeFromAction(GetRandomAction(r, eightDirections))
 			}
 		} else {
 			// change action type
-			node.NodeType = GetRandomAction()
+			node.NodeType = GetRandomAction(r, eightDirections)
 		}
 	} else {
-		if rand.Intn(2) == 0 {
+		if r.Intn(2) == 0 {
 			// convert condition to action (simplify)
-			node.NodeType = GetRandomAction()
+			node.NodeType = GetRandomAction(r, eightDirections)
 			node.YesNode = nil
 			node.NoNode = nil
 		} else {
 			// change condition type
-			node.NodeType = GetRandomCondition()
+			node.NodeType = GetRandomCondition(r, eightDirections)
 		}
 	}
 
@@ -87,3 +86,19 @@ func (t *Tree) Size() int {
 func (t *Tree) Print() string {
 	return t.print("", true, false)
 }
+
+// TreeFromString parses a string generated by Serialize and returns the
+// decision Tree it represents
+func TreeFromString(serialized string) (*Tree, error) {
+	node, remaining, err := nodeFromString(serialized)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("unexpected trailing nodes in decision tree %q", serialized)
+	}
+	tree := &Tree{Node: node}
+	tree.size = tree.CalcAndUpdateSize()
+	tree.ID = tree.Serialize()
+	return tree, nil
+}
@@ -16,14 +16,29 @@ func (n *Node) CalcAndUpdateSize() int {
 	return n.size
 }
 
-// GetRandomCondition returns a random Condition from the Conditions array
-func GetRandomCondition() Condition {
-	return Conditions[rand.Intn(len(Conditions))]
+// GetRandomCondition returns a random Condition from the Conditions array,
+// or from Conditions and DiagonalConditions if eightDirections is true
+func GetRandomCondition(r *rand.Rand, eightDirections bool) Condition {
+	if eightDirections {
+		return eightDirectionConditions[r.Intn(len(eightDirectionConditions))]
+	}
+	return Conditions[r.Intn(len(Conditions))]
 }
 
-// GetRandomAction returns a random Action from the Actions array
-func GetRandomAction() Action {
-	return Actions[rand.Intn(len(Actions))]
+// GetRandomAction returns a random Action from the Actions array, or from
+// Actions and DiagonalActions if eightDirections is true
+func GetRandomAction(r *rand.Rand, eightDirections bool) Action {
+	actions := ChoosableActions(eightDirections)
+	return actions[r.Intn(len(actions))]
+}
+
+// ChoosableActions returns the actions organisms may choose from: Actions, and
+// DiagonalActions too if eightDirections is true
+func ChoosableActions(eightDirections bool) []Action {
+	if eightDirections {
+		return eightDirectionActions
+	}
+	return Actions[:]
 }
 
 // isAction returns true if the object passed in is an Action
@@ -43,3 +58,22 @@ func isCondition(v interface{}) bool {
 	}
 	return false
 }
+
+// nodeTypeFromInt returns the Action or Condition with the given value and
+// whether one was found
+func nodeTypeFromInt(value int) (interface{}, bool) {
+	for _, action := range eightDirectionActions {
+		if int(action) == value {
+			return action, true
+		}
+	}
+	if value == int(ActSpawn) {
+		return ActSpawn, true
+	}
+	for _, condition := range eightDirectionConditions {
+		if int(condition) == value {
+			return condition, true
+		}
+	}
+	return nil, false
+}
@@ -1,9 +1,14 @@
 package environment
 
-import "github.com/Zebbeni/protozoa/utils"
+import (
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/utils"
+)
 
 // API provides functions to look up information about the sim state
 type API interface {
+	Config() *config.Globals
+	Geometry() *utils.Geometry
 	Cycle() int
 	AddPhUpdate(p utils.Point)
 }
@@ -0,0 +1,168 @@
+package environment
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/Zebbeni/protozoa/config"
+)
+
+// noiseOctaves is the number of layers of detail added to noise landscapes,
+// each half the size and strength of the last
+const noiseOctaves = 4
+
+// GenerateLandscape returns a width x height map of levels from 0 to 1,
+// indexed [x][y], generated as described by a landscape
+func GenerateLandscape(l config.Landscape, width, height int) ([][]float64, error) {
+	levels := make([][]float64, width)
+	for x := range levels {
+		levels[x] = make([]float64, height)
+	}
+
+	var level func(x, y int) float64
+	switch l.Generator {
+	case "", config.LandscapeUniform:
+		level = func(_, _ int) float64 { return 0.5 }
+	case config.LandscapeRandom:
+		random := rand.New(rand.NewSource(l.Seed))
+		level = func(_, _ int) float64 { return random.Float64() }
+	case config.LandscapeGradient:
+		level = gradient(l.Angle, width, height)
+	case config.LandscapeRadial:
+		level = radial(width, height)
+	case config.LandscapeNoise:
+		level = noise(l.Seed, l.Scale, width, height)
+	case config.LandscapeImage:
+		img, err := config.LoadLevelImage(l.Image)
+		if err != nil {
+			return nil, err
+		}
+		// stretch the image over the grid
+		level = func(x, y int) float64 {
+			return img.LevelAt(x*img.Width/width, y*img.Height/height)
+		}
+	default:
+		return nil, fmt.Errorf("unknown landscape generator %q", l.Generator)
+	}
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			levels[x][y] = level(x, y)
+		}
+	}
+	if l.Generator == config.LandscapeNoise {
+		normalize(levels)
+	}
+	if l.Invert {
+		for x := range levels {
+			for y := range levels[x] {
+				levels[x][y] = 1 - levels[x][y]
+			}
+		}
+	}
+	return levels, nil
+}
+
+// gradient returns levels rising linearly across the grid in the direction of
+// the given angle, from 0 at one edge or corner to 1 at the opposite one
+func gradient(angle float64, width, height int) func(x, y int) float64 {
+	radians := angle * math.Pi / 180
+	dx, dy := math.Cos(radians), math.Sin(radians)
+	project := func(x, y float64) float64 {
+		return x*dx + y*dy
+	}
+	// the lowest and highest projections are always at corners
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		p := project(corner[0]*float64(width-1), corner[1]*float64(height-1))
+		low, high = math.Min(low, p), math.Max(high, p)
+	}
+	return func(x, y int) float64 {
+		if high == low {
+			return 0.5
+		}
+		return (project(float64(x), float64(y)) - low) / (high - low)
+	}
+}
+
+// radial returns levels rising from 0 at the centre of the grid to 1 at its
+// corners
+func radial(width, height int) func(x, y int) float64 {
+	centerX, centerY := float64(width-1)/2, float64(height-1)/2
+	farthest := math.Hypot(centerX, centerY)
+	return func(x, y int) float64 {
+		if farthest == 0 {
+			return 0
+		}
+		return math.Hypot(float64(x)-centerX, float64(y)-centerY) / farthest
+	}
+}
+
+// noise returns smooth fractal value noise whose largest features are about
+// scale units across. The noise tiles across the edges of the grid, since
+// organisms and pH wrap around them unless the world is bounded.
+func noise(seed int64, scale float64, width, height int) func(x, y int) float64 {
+	cellsWide := int(math.Max(1, math.Round(float64(width)/scale)))
+	cellsHigh := int(math.Max(1, math.Round(float64(height)/scale)))
+	return func(x, y int) float64 {
+		total, strength := 0.0, 1.0
+		for octave := 0; octave < noiseOctaves; octave++ {
+			wide, high := cellsWide<<octave, cellsHigh<<octave
+			u := float64(x) * float64(wide) / float64(width)
+			v := float64(y) * float64(high) / float64(height)
+			total += strength * smoothValue(seed+int64(octave), u, v, wide, high)
+			strength /= 2
+		}
+		return total
+	}
+}
+
+// smoothValue interpolates between random values at the integer lattice
+// points around (u, v), wrapping the lattice every wide x high points
+func smoothValue(seed int64, u, v float64, wide, high int) float64 {
+	u0, v0 := math.Floor(u), math.Floor(v)
+	x0, y0 := int(u0)%wide, int(v0)%high
+	x1, y1 := (x0+1)%wide, (y0+1)%high
+	fu, fv := smoothStep(u-u0), smoothStep(v-v0)
+	top := lerp(latticeValue(seed, x0, y0), latticeValue(seed, x1, y0), fu)
+	bottom := lerp(latticeValue(seed, x0, y1), latticeValue(seed, x1, y1), fu)
+	return lerp(top, bottom, fv)
+}
+
+// latticeValue returns a repeatable pseudo-random value from 0 to 1 for a
+// lattice point
+func latticeValue(seed int64, x, y int) float64 {
+	h := uint64(seed)*0x9e3779b97f4a7c15 ^ uint64(x)*0xbf58476d1ce4e5b9 ^ uint64(y)*0x94d049bb133111eb
+	h ^= h >> 31
+	h *= 0xd6e8feb86659fd93
+	h ^= h >> 32
+	return float64(h>>11) / (1 << 53)
+}
+
+func smoothStep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// normalize stretches levels to fill the range from 0 to 1
+func normalize(levels [][]float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for x := range levels {
+		for _, level := range levels[x] {
+			low, high = math.Min(low, level), math.Max(high, level)
+		}
+	}
+	for x := range levels {
+		for y := range levels[x] {
+			if high > low {
+				levels[x][y] = (levels[x][y] - low) / (high - low)
+			} else {
+				levels[x][y] = 0.5
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package environment
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Zebbeni/protozoa/config"
+)
+
+func TestGenerateLandscape(t *testing.T) {
+	const width, height = 40, 30
+	generate := func(l config.Landscape) [][]float64 {
+		t.Helper()
+		levels, err := GenerateLandscape(l, width, height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for x := range levels {
+			for y, level := range levels[x] {
+				if level < 0 || level > 1 {
+					t.Fatalf("%s level at %d,%d is %g, outside 0 to 1", l.Generator, x, y, level)
+				}
+			}
+		}
+		return levels
+	}
+
+	if levels := generate(config.Landscape{}); levels[3][7] != 0.5 {
+		t.Errorf("expected a uniform landscape to be 0.5, found %g", levels[3][7])
+	}
+
+	levels := generate(config.Landscape{Generator: config.LandscapeGradient})
+	if levels[0][5] != 0 || levels[width-1][5] != 1 || levels[10][0] != levels[10][height-1] {
+		t.Errorf("expected an angle 0 gradient to rise left to right only")
+	}
+	levels = generate(config.Landscape{Generator: config.LandscapeGradient, Angle: 90, Invert: true})
+	if math.Abs(levels[5][0]-1) > 1e-9 || math.Abs(levels[5][height-1]) > 1e-9 {
+		t.Errorf("expected an inverted angle 90 gradient to fall top to bottom")
+	}
+
+	levels = generate(config.Landscape{Generator: config.LandscapeRadial})
+	if levels[0][0] != 1 || levels[width/2][height/2] > 0.1 {
+		t.Errorf("expected a radial landscape to rise from the centre to the corners")
+	}
+
+	noise := config.Landscape{Generator: config.LandscapeNoise, Scale: 10, Seed: 7}
+	first, second := generate(noise), generate(noise)
+	noise.Seed = 8
+	other := generate(noise)
+	same, differs := true, false
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			same = same && first[x][y] == second[x][y]
+			differs = differs || first[x][y] != other[x][y]
+		}
+	}
+	if !same || !differs {
+		t.Errorf("expected noise to repeat with the same seed and change with another")
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Set(1, 0, color.White)
+	path := filepath.Join(t.TempDir(), "ph.png")
+	file, _ := os.Create(path)
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	levels = generate(config.Landscape{Generator: config.LandscapeImage, Image: path})
+	if levels[0][0] != 0 || levels[width-1][height-1] != 1 {
+		t.Errorf("expected the image to be stretched over the grid, found %g and %g", levels[0][0], levels[width-1][height-1])
+	}
+}
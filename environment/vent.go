@@ -0,0 +1,17 @@
+package environment
+
+import "github.com/Zebbeni/protozoa/utils"
+
+// Vent is a vent placed on the grid, changing pH or another field
+type Vent struct {
+	Field    string // name of the field changed, "" for pH
+	Point    utils.Point
+	Strength float64
+	Radius   int
+}
+
+// IsSource returns true if the vent raises the value around it, or false if
+// it is a sink that lowers it
+func (v Vent) IsSource() bool {
+	return v.Strength > 0
+}
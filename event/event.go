@@ -0,0 +1,109 @@
+// Package event defines the events a simulation reports to its observers as
+// organisms are born, die and act on each other and their environment
+package event
+
+import "github.com/Zebbeni/protozoa/utils"
+
+// Type is the custom type for all kinds of events
+type Type int
+
+// Define all kinds of events
+const (
+	// Spawned is reported when a new organism is created
+	Spawned Type = iota
+	// Died is reported when an organism's health falls to zero
+	Died
+	// Attacked is reported when an organism attacks another organism
+	Attacked
+	// Fed is reported when an organism feeds another organism
+	Fed
+	// Ate is reported when an organism eats food
+	Ate
+	// Moved is reported when an organism moves to a new location
+	Moved
+)
+
+// Cause is the custom type for the causes of an organism's death
+type Cause int
+
+// Define all causes of death
+const (
+	// CauseNone is used by all events other than Died
+	CauseNone Cause = iota
+	// CauseAttack means an organism was killed by attacks from others
+	CauseAttack
+	// CausePh means an organism died from living in unhealthy pH
+	CausePh
+	// CauseUpkeep means an organism died from the cost of its decision tree
+	CauseUpkeep
+	// CauseExertion means an organism died from the cost of its own action
+	CauseExertion
+	// CauseEnvironment means an organism died from living in unhealthy
+	// values of fields other than pH
+	CauseEnvironment
+)
+
+var typeNames = map[Type]string{
+	Spawned:  "Spawned",
+	Died:     "Died",
+	Attacked: "Attacked",
+	Fed:      "Fed",
+	Ate:      "Ate",
+	Moved:    "Moved",
+}
+
+var causeNames = map[Cause]string{
+	CauseNone:        "None",
+	CauseAttack:      "Attack",
+	CausePh:          "Ph",
+	CauseUpkeep:      "Upkeep",
+	CauseExertion:    "Exertion",
+	CauseEnvironment: "Environment",
+}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+func (c Cause) String() string {
+	return causeNames[c]
+}
+
+// Event describes something that happened to an organism during a cycle.
+// Fields that don't apply to an event's Type are left at their zero values,
+// except for IDs, which are -1 when they don't apply.
+type Event struct {
+	Type  Type
+	Cycle int
+	// OrganismID is the organism the event happened to or was caused by
+	OrganismID int
+	// ParentID is the parent of a Spawned organism, or -1 if it has none
+	ParentID int
+	// TargetID is the organism Attacked or Fed, or -1 if there is none
+	TargetID int
+	// Location is where the organism was after the event took place
+	Location utils.Point
+	// From is where a Moved organism was before moving
+	From utils.Point
+	// Target is the point an attack, feed or meal was directed at
+	Target utils.Point
+	// Amount is the health taken by an attack, given by a feed, or the
+	// amount of food eaten
+	Amount float64
+	// Cause is the reason an organism Died
+	Cause Cause
+}
+
+// Observer receives the events reported by a simulation
+type Observer interface {
+	// OnEvent is called once for each event, in the order they occurred
+	OnEvent(e Event)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface
+type ObserverFunc func(e Event)
+
+// OnEvent calls f(e)
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
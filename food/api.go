@@ -1,8 +1,13 @@
 package food
 
-import "github.com/Zebbeni/protozoa/utils"
+import (
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/utils"
+)
 
 // API provides functions to look up or update information for the sim state
 type API interface {
+	Config() *config.Globals
+	Geometry() *utils.Geometry
 	AddFoodUpdate(p utils.Point)
 }
@@ -2,39 +2,55 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
+	"strings"
 
 	"github.com/Zebbeni/protozoa/config"
 	"github.com/Zebbeni/protozoa/runner"
+	"github.com/Zebbeni/protozoa/simulation"
 )
 
-var opts *config.Options
+var (
+	opts    *config.Options
+	globals *config.Globals
+)
 
 func main() {
-	runner.RunSimulation(opts)
+	runner.RunSimulation(opts, globals)
 }
 
 func init() {
 	opts = config.GetOptions()
 
-	if opts.DumpConfig {
-		g := config.GetDefaultGlobals()
-		config.DumpGlobals(&g, os.Stdout)
-		os.Exit(0)
-	}
-
-	var globals *config.Globals
-	if opts.ConfigFile != "" {
-		file := config.LoadFile(opts.ConfigFile)
-		globals = config.LoadGlobals(file)
+	var err error
+	if opts.ResumeFile != "" {
+		globals, err = loadResumedGlobals()
 	} else {
-		p := config.GetDefaultGlobals()
-		globals = &p
+		globals, err = config.Load(opts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	config.SetGlobals(globals)
+	if opts.DumpConfig {
+		config.DumpGlobals(globals, os.Stdout)
+		os.Exit(0)
+	}
 
 	fmt.Println("Seed:", int64(opts.Seed))
-	rand.Seed(int64(opts.Seed))
+}
+
+// loadResumedGlobals returns the settings saved in the snapshot being resumed,
+// which can't be overridden
+func loadResumedGlobals() (*config.Globals, error) {
+	if sources := config.OverrideSources(opts); len(sources) > 0 {
+		return nil, fmt.Errorf("resumed simulations keep the settings they were saved with, so settings can't be given with -resume: found %s",
+			strings.Join(sources, ", "))
+	}
+	header, err := simulation.ReadSnapshotHeader(opts.ResumeFile)
+	if err != nil {
+		return nil, err
+	}
+	return &header.Globals, nil
 }
@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"fmt"
+
 	c "github.com/Zebbeni/protozoa/config"
 	"github.com/Zebbeni/protozoa/environment"
 	"github.com/Zebbeni/protozoa/utils"
@@ -8,47 +10,154 @@ import (
 	"sync"
 )
 
+// maxVentPlacementAttempts is the number of random locations tried when
+// placing a vent before giving up, in case the grid is mostly walls
+const maxVentPlacementAttempts = 1000
+
+// diffusionOffsets are the N,S,E,W neighbors values diffuse between
+var diffusionOffsets = []utils.Point{{X: 0, Y: 1}, {X: 0, Y: -1}, {X: 1, Y: 0}, {X: -1, Y: 0}}
+
 // EnvironmentManager contains an image
 type EnvironmentManager struct {
-	api environment.API
+	api      environment.API
+	cfg      *c.Globals
+	geometry *utils.Geometry
 
 	currentPhMap  [][]float64
 	previousPhMap [][]float64
 
 	averagePh float64
 
+	fields []fieldMap
+
+	lightLevels [][]float64
+
+	vents []environment.Vent
+
 	mutex sync.Mutex
 }
 
-func NewEnvironmentManager(api environment.API) *EnvironmentManager {
+// NewEnvironmentManager returns an EnvironmentManager with a new pH map, using
+// the given random stream to place any vents with random locations
+func NewEnvironmentManager(api environment.API, random *utils.Random) *EnvironmentManager {
 	manager := &EnvironmentManager{
-		api: api,
+		api:      api,
+		cfg:      api.Config(),
+		geometry: api.Geometry(),
 	}
 
 	manager.initializePhMap()
+	manager.initializeFields()
+	manager.initializeLight()
+	manager.placeVents(random)
 
 	return manager
 }
 
+// initializePhMap sets the starting pH of every location from the configured
+// landscape, scaled between the minimum and maximum initial pH
 func (m *EnvironmentManager) initializePhMap() {
-	gridW, gridH := c.GridUnitsWide(), c.GridUnitsHigh()
+	gridW, gridH := m.cfg.GridUnitsWide, m.cfg.GridUnitsHigh
+	levels, err := environment.GenerateLandscape(m.cfg.InitialPhLandscape, gridW, gridH)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate initial pH landscape: %v", err))
+	}
 	m.previousPhMap = make([][]float64, gridW)
 	m.currentPhMap = make([][]float64, gridW)
 	for x := 0; x < gridW; x++ {
 		m.previousPhMap[x] = make([]float64, gridH)
 		m.currentPhMap[x] = make([]float64, gridH)
 		for y := 0; y < gridH; y++ {
-			// Start all locations at neutral ph
-			val := (c.MaxInitialPh() + c.MinInitialPh()) / 2.0
+			val := m.cfg.MinInitialPh + levels[x][y]*(m.cfg.MaxInitialPh-m.cfg.MinInitialPh)
 			m.previousPhMap[x][y] = val
 			m.currentPhMap[x][y] = val
 		}
 	}
 }
 
+// placeVents places every configured vent on the grid, choosing open
+// locations for vents without a fixed one
+func (m *EnvironmentManager) placeVents(random *utils.Random) {
+	m.vents = make([]environment.Vent, 0, len(m.cfg.Vents))
+	for _, vent := range m.cfg.Vents {
+		if !vent.Random {
+			m.addVent(utils.Point{X: vent.X, Y: vent.Y}, vent)
+			continue
+		}
+		count := vent.Count
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			if point, ok := m.getRandomOpenLocation(random); ok {
+				m.addVent(point, vent)
+			}
+		}
+	}
+}
+
+func (m *EnvironmentManager) addVent(point utils.Point, vent c.Vent) {
+	m.vents = append(m.vents, environment.Vent{
+		Field:    vent.Field,
+		Point:    point,
+		Strength: vent.Strength,
+		Radius:   vent.Radius,
+	})
+}
+
+// getRandomOpenLocation returns a random point that is not on a wall, giving
+// up after maxVentPlacementAttempts tries
+func (m *EnvironmentManager) getRandomOpenLocation(random *utils.Random) (utils.Point, bool) {
+	for i := 0; i < maxVentPlacementAttempts; i++ {
+		point := utils.GetRandomPoint(random.Rand, m.geometry.Width(), m.geometry.Height())
+		if !m.geometry.IsWall(point) {
+			return point, true
+		}
+	}
+	return utils.Point{}, false
+}
+
 func (m *EnvironmentManager) Update() {
 	m.updatePrevCurrentPhMaps()
 	m.diffusePhLevels()
+	m.applyVents()
+	m.UpdateFields()
+}
+
+// GetVents returns every vent placed on the grid
+func (m *EnvironmentManager) GetVents() []environment.Vent {
+	return m.vents
+}
+
+// applyVents changes the pH around each pH vent by its strength, fading
+// linearly with distance so that locations just beyond its radius are
+// unaffected. Walls are skipped, since pH on walls only reflects its
+// surroundings.
+func (m *EnvironmentManager) applyVents() {
+	for _, vent := range m.vents {
+		if vent.Field == "" {
+			m.applyVent(vent, m.AddPhChangeAtPoint)
+		}
+	}
+}
+
+// applyVent passes the change a vent makes to every open location it reaches
+// to the given add function
+func (m *EnvironmentManager) applyVent(vent environment.Vent, add func(point utils.Point, change float64)) {
+	for dx := -vent.Radius; dx <= vent.Radius; dx++ {
+		for dy := -vent.Radius; dy <= vent.Radius; dy++ {
+			distance := math.Hypot(float64(dx), float64(dy))
+			if distance > float64(vent.Radius) {
+				continue
+			}
+			point := m.geometry.Add(vent.Point, utils.Point{X: dx, Y: dy})
+			if m.geometry.IsWall(point) {
+				continue
+			}
+			falloff := 1 - distance/float64(vent.Radius+1)
+			add(point, vent.Strength*falloff)
+		}
+	}
 }
 
 func (m *EnvironmentManager) GetPhMap() [][]float64 {
@@ -56,15 +165,14 @@ func (m *EnvironmentManager) GetPhMap() [][]float64 {
 }
 
 func (m *EnvironmentManager) GetWalls() []utils.Point {
-	if c.UsePools() == false {
+	if !m.geometry.HasWalls() {
 		return []utils.Point{}
 	}
 
-	max := (c.GridUnitsWide() / c.PoolWidth()) * (c.GridUnitsHigh() / c.PoolHeight())
-	points := make([]utils.Point, 0, max)
-	for x := 0; x < c.GridUnitsWide(); x++ {
-		for y := 0; y < c.GridUnitsHigh(); y++ {
-			if utils.IsWall(x, y) {
+	points := make([]utils.Point, 0)
+	for x := 0; x < m.cfg.GridUnitsWide; x++ {
+		for y := 0; y < m.cfg.GridUnitsHigh; y++ {
+			if m.geometry.IsWallAt(x, y) {
 				points = append(points, utils.Point{X: x, Y: y})
 			}
 		}
@@ -90,10 +198,10 @@ func (m *EnvironmentManager) AddPhChangeAtPoint(point utils.Point, change float6
 
 func (m *EnvironmentManager) setPhAtPoint(point utils.Point, val float64) {
 	prevPh := m.getPreviousPh(point)
-	newPh := math.Max(math.Min(val, c.MaxPh()), c.MinPh())
+	newPh := math.Max(math.Min(val, m.cfg.MaxPh), m.cfg.MinPh)
 
 	// only flag a worthwhile update if change is passed the threshold to update
-	incrementToDisplay := c.PhIncrementToDisplay()
+	incrementToDisplay := m.cfg.PhIncrementToDisplay
 	if int(prevPh/incrementToDisplay) != int(newPh/incrementToDisplay) {
 		m.addUpdatedPoint(point)
 	}
@@ -138,73 +246,68 @@ func (m *EnvironmentManager) updatePrevCurrentPhMaps() {
 // ph value toward its neighbors' values.
 // Also, while iterating, calculates average ph in environment
 func (m *EnvironmentManager) diffusePhLevels() {
-	gridW, gridH := c.GridUnitsWide(), c.GridUnitsHigh()
-	diffFactor := c.PhDiffuseFactor()
+	m.averagePh = m.diffuse(m.previousPhMap, m.cfg.PhDiffuseFactor, m.setPhAtPoint)
+}
 
-	adjPh := func(x, y int) (float64, bool) {
-		return m.previousPhMap[x][y], !utils.IsWall(x, y)
+// diffuse sets each value of a map, through the given set function, to its
+// previous value plus the average difference between itself and its N,S,E,W
+// neighbors, times the diffusion factor. Returns the average previous value.
+func (m *EnvironmentManager) diffuse(previous [][]float64, diffFactor float64, set func(point utils.Point, val float64)) float64 {
+	gridW, gridH := m.cfg.GridUnitsWide, m.cfg.GridUnitsHigh
+
+	// return the sum of adjacent values, skipping walls unless includeWalls
+	// is set, along with the number summed. On a bounded grid, points past
+	// its edge are skipped, so nothing diffuses across it.
+	adjVals := func(x, y int, includeWalls bool) (float64, int) {
+		sum, count := 0.0, 0
+		for _, offset := range diffusionOffsets {
+			p := m.geometry.Add(utils.Point{X: x, Y: y}, offset)
+			if !m.geometry.InBounds(p) {
+				continue
+			}
+			if includeWalls || !m.geometry.IsWallAt(p.X, p.Y) {
+				sum += previous[p.X][p.Y]
+				count++
+			}
+		}
+		return sum, count
 	}
 
 	// return average of all diffuse-able adjacent points
-	avgAdjPh := func(x, y int) float64 {
-		neighbors := 0
-		avgPh := 0.0
-		if ph, ok := adjPh(x, (y+1)%gridH); ok {
-			avgPh += ph
-			neighbors++
-		}
-		if ph, ok := adjPh(x, (y+gridH-1)%gridH); ok {
-			avgPh += ph
-			neighbors++
-		}
-		if ph, ok := adjPh((x+1)%gridW, y); ok {
-			avgPh += ph
-			neighbors++
-		}
-		if ph, ok := adjPh((x+gridW-1)%gridW, y); ok {
-			avgPh += ph
-			neighbors++
+	avgAdjVal := func(x, y int) float64 {
+		sum, neighbors := adjVals(x, y, false)
+		if neighbors == 0 {
+			// enclosed by walls, so there is nothing to diffuse with
+			return previous[x][y]
 		}
-		return avgPh / float64(neighbors)
+		return sum / float64(neighbors)
 	}
 
-	// return average ph of all adjacent points (even if in walls)
-	avgAdjPhAll := func(x, y int) float64 {
-		avgPh := 0.0
-		ph, _ := adjPh(x, (y+1)%gridH)
-		avgPh += ph
-		ph, _ = adjPh(x, (y+gridH-1)%gridH)
-		avgPh += ph
-		ph, _ = adjPh((x+1)%gridW, y)
-		avgPh += ph
-		ph, _ = adjPh((x+gridW-1)%gridW, y)
-		avgPh += ph
-		return avgPh / 4.0
+	// return average value of all adjacent points (even if in walls)
+	avgAdjValAll := func(x, y int) float64 {
+		sum, neighbors := adjVals(x, y, true)
+		return sum / float64(neighbors)
 	}
 
-	totalPh := 0.0
+	total := 0.0
 	pointCount := float64(gridW * gridH)
-	// set each value in the current phMap to its value in the previous phMap, plus
-	// the average difference between itself and its N,S,E,W neighbors (times the
-	// diffusion factor provided by the config)
 	for x := 0; x < gridW; x++ {
 		for y := 0; y < gridH; y++ {
-			prevVal := m.previousPhMap[x][y]
-			totalPh += prevVal
+			prevVal := previous[x][y]
+			total += prevVal
 
-			// Just set wall ph to the average of its neighbors
+			// Just set wall values to the average of their neighbors
 			// (doesn't really affect anything but appearance, since we don't
 			// diffuse this value back to the rest of the environment
-			if utils.IsWall(x, y) {
-				m.setPhAtPoint(utils.Point{X: x, Y: y}, avgAdjPhAll(x, y))
+			if m.geometry.IsWallAt(x, y) {
+				set(utils.Point{X: x, Y: y}, avgAdjValAll(x, y))
 				continue
 			}
 
-			avgAdjacentPh := avgAdjPh(x, y)
-			change := (avgAdjacentPh - prevVal) * diffFactor
-			m.setPhAtPoint(utils.Point{X: x, Y: y}, prevVal+change)
+			change := (avgAdjVal(x, y) - prevVal) * diffFactor
+			set(utils.Point{X: x, Y: y}, prevVal+change)
 		}
 	}
 
-	m.averagePh = totalPh / pointCount
+	return total / pointCount
 }
@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Zebbeni/protozoa/environment"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// fieldMap holds the values of one of the environment's fields other than pH.
+// Unlike pH, fields are only changed by diffusion and vents, so they need no
+// locking while organisms update.
+type fieldMap struct {
+	current, previous [][]float64
+	average           float64
+}
+
+// initializeFields sets the starting values of every configured field from
+// its landscape, scaled between the field's minimum and maximum initial values
+func (m *EnvironmentManager) initializeFields() {
+	gridW, gridH := m.cfg.GridUnitsWide, m.cfg.GridUnitsHigh
+	m.fields = make([]fieldMap, len(m.cfg.Fields))
+	for i, field := range m.cfg.Fields {
+		levels, err := environment.GenerateLandscape(field.Landscape, gridW, gridH)
+		if err != nil {
+			panic(fmt.Sprintf("failed to generate initial %s landscape: %v", field.Name, err))
+		}
+		m.fields[i].current = make([][]float64, gridW)
+		m.fields[i].previous = make([][]float64, gridW)
+		for x := 0; x < gridW; x++ {
+			m.fields[i].current[x] = make([]float64, gridH)
+			m.fields[i].previous[x] = make([]float64, gridH)
+			for y := 0; y < gridH; y++ {
+				val := field.MinInitial + levels[x][y]*(field.MaxInitial-field.MinInitial)
+				m.fields[i].current[x][y] = val
+				m.fields[i].previous[x][y] = val
+			}
+		}
+	}
+}
+
+// UpdateFields diffuses every field and applies the vents that change them.
+// Fields depend on nothing but their previous values, so replays update them
+// this way too instead of recording them.
+func (m *EnvironmentManager) UpdateFields() {
+	for i := range m.fields {
+		f := &m.fields[i]
+		f.previous, f.current = f.current, f.previous
+		f.average = m.diffuse(f.previous, m.cfg.Fields[i].DiffuseFactor, func(point utils.Point, val float64) {
+			m.setFieldAtPoint(i, point, val)
+		})
+	}
+	for _, vent := range m.vents {
+		if i, ok := m.cfg.FieldIndex(vent.Field); ok && vent.Field != "" {
+			m.applyVent(vent, func(point utils.Point, change float64) {
+				m.setFieldAtPoint(i, point, m.fields[i].current[point.X][point.Y]+change)
+			})
+		}
+	}
+}
+
+// setFieldAtPoint sets the current value of a field at a given point, bounded
+// by the field's minimum and maximum values
+func (m *EnvironmentManager) setFieldAtPoint(field int, point utils.Point, val float64) {
+	cfg := m.cfg.Fields[field]
+	m.fields[field].current[point.X][point.Y] = math.Max(math.Min(val, cfg.Max), cfg.Min)
+}
+
+// GetFieldAtPoint returns the current value of a field at a given point
+func (m *EnvironmentManager) GetFieldAtPoint(field int, point utils.Point) float64 {
+	return m.fields[field].current[point.X][point.Y]
+}
+
+// GetFieldMap returns the current values of a field across the grid
+func (m *EnvironmentManager) GetFieldMap(field int) [][]float64 {
+	return m.fields[field].current
+}
+
+// GetAverageField returns the average value of a field across the grid
+func (m *EnvironmentManager) GetAverageField(field int) float64 {
+	return m.fields[field].average
+}
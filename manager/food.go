@@ -2,7 +2,6 @@ package manager
 
 import (
 	"math"
-	"math/rand"
 	"sync"
 
 	"github.com/Zebbeni/protozoa/config"
@@ -15,18 +14,25 @@ type FoodManager struct {
 	api           food.API
 	Items         map[string]*food.Item
 	isInitialized bool
+	cfg           *config.Globals
+	geometry      *utils.Geometry
+	random        *utils.Random
 
 	mutex sync.RWMutex
 }
 
-// NewFoodManager initializes a new foodItem map of MinFood
-func NewFoodManager(api food.API) *FoodManager {
+// NewFoodManager initializes a new foodItem map of MinFood, drawing all random
+// food placement from the given stream
+func NewFoodManager(api food.API, random *utils.Random) *FoodManager {
 	m := &FoodManager{
 		api:           api,
 		Items:         make(map[string]*food.Item),
 		isInitialized: false,
+		cfg:           api.Config(),
+		geometry:      api.Geometry(),
+		random:        random,
 	}
-	m.InitializeFood(config.InitialFood())
+	m.InitializeFood(m.cfg.InitialFood)
 	return m
 }
 
@@ -38,7 +44,7 @@ func (m *FoodManager) InitializeFood(count int) {
 
 // Update is called on every cycle and adds new FoodItems at a constant rate
 func (m *FoodManager) Update() {
-	if rand.Float64() < config.ChanceToAddFoodItem() {
+	if m.random.Float64() < m.cfg.ChanceToAddFoodItem {
 		m.AddRandomFoodItem()
 	}
 	return
@@ -52,9 +58,9 @@ func (m *FoodManager) FoodCount() int {
 // AddRandomFoodItem attempts to add a FoodItem object to a random location
 // Gives up if first attempt to place food fails.
 func (m *FoodManager) AddRandomFoodItem() {
-	x := rand.Intn(config.GridUnitsWide())
-	y := rand.Intn(config.GridUnitsHigh())
-	value := rand.Intn(config.MaxFoodValue())
+	x := m.random.Intn(m.cfg.GridUnitsWide)
+	y := m.random.Intn(m.cfg.GridUnitsHigh)
+	value := m.random.Intn(m.cfg.MaxFoodValue)
 	point := utils.Point{X: x, Y: y}
 	m.addFood(point, value)
 }
@@ -82,7 +88,7 @@ func (m *FoodManager) GetFoodItems() map[string]*food.Item {
 }
 
 func (m *FoodManager) removeFood(point utils.Point, value int) {
-	if value <= 0 || point.IsWall() {
+	if value <= 0 || m.geometry.IsWall(point) {
 		return
 	}
 
@@ -97,7 +103,7 @@ func (m *FoodManager) removeFood(point utils.Point, value int) {
 	}
 
 	item.Value -= value
-	if item.Value <= config.MinFoodValue() {
+	if item.Value <= m.cfg.MinFoodValue {
 		m.mutex.Lock()
 		delete(m.Items, pointString)
 		m.mutex.Unlock()
@@ -107,7 +113,7 @@ func (m *FoodManager) removeFood(point utils.Point, value int) {
 }
 
 func (m *FoodManager) addFood(point utils.Point, value int) {
-	if value <= 0 || point.IsWall() {
+	if value <= 0 || m.geometry.IsWall(point) {
 		return
 	}
 
@@ -118,7 +124,7 @@ func (m *FoodManager) addFood(point utils.Point, value int) {
 	if exists {
 		value += item.Value
 	}
-	value = int(math.Min(math.Max(0.0, float64(value)), float64(config.MaxFoodValue())))
+	value = int(math.Min(math.Max(0.0, float64(value)), float64(m.cfg.MaxFoodValue)))
 	m.Items[pointString] = food.NewItem(point, value)
 	m.mutex.Unlock()
 
@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Zebbeni/protozoa/environment"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// initializeLight generates the light levels of every location from the
+// configured landscape. Light isn't saved with the rest of the environment,
+// since it only depends on the config and cycle.
+func (m *EnvironmentManager) initializeLight() {
+	levels, err := environment.GenerateLandscape(m.cfg.LightLandscape, m.cfg.GridUnitsWide, m.cfg.GridUnitsHigh)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate light landscape: %v", err))
+	}
+	m.lightLevels = levels
+}
+
+// GetLightAtPoint returns the light at a given point on the current cycle,
+// before any shade from organisms
+func (m *EnvironmentManager) GetLightAtPoint(point utils.Point) float64 {
+	level := m.lightLevels[point.X][point.Y]
+	return (m.cfg.MinLight + level*(m.cfg.MaxLight-m.cfg.MinLight)) * m.GetDaylight()
+}
+
+// GetDaylight returns the fraction of noon light shining on the current
+// cycle, following a smooth day and night cycle that starts at noon
+func (m *EnvironmentManager) GetDaylight() float64 {
+	if m.cfg.DayLength <= 0 {
+		return 1
+	}
+	timeOfDay := float64(m.api.Cycle()%m.cfg.DayLength) / float64(m.cfg.DayLength)
+	brightness := 0.5 + 0.5*math.Cos(2*math.Pi*timeOfDay)
+	return m.cfg.NightLight + (1-m.cfg.NightLight)*brightness
+}
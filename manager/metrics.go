@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"math"
+	"strings"
+
+	c "github.com/Zebbeni/protozoa/config"
+	d "github.com/Zebbeni/protozoa/decision"
+	"github.com/Zebbeni/protozoa/organism"
+)
+
+// Metric is a single named statistic
+type Metric struct {
+	Name  string
+	Value float64
+}
+
+// traitMetric is a numeric organism trait included in metrics
+type traitMetric struct {
+	name  string
+	value func(t organism.Traits) float64
+}
+
+// baseTraitMetrics lists every numeric organism trait included in metrics,
+// apart from the traits for the config's fields
+var baseTraitMetrics = []traitMetric{
+	{"max_size", func(t organism.Traits) float64 { return t.MaxSize }},
+	{"spawn_health", func(t organism.Traits) float64 { return t.SpawnHealth }},
+	{"min_health_to_spawn", func(t organism.Traits) float64 { return t.MinHealthToSpawn }},
+	{"min_cycles_between_spawns", func(t organism.Traits) float64 { return float64(t.MinCyclesBetweenSpawns) }},
+	{"chance_to_mutate_decision_tree", func(t organism.Traits) float64 { return t.ChanceToMutateDecisionTree }},
+	{"ideal_ph", func(t organism.Traits) float64 { return t.IdealPh }},
+	{"ph_tolerance", func(t organism.Traits) float64 { return t.PhTolerance }},
+	{"ph_growth_effect", func(t organism.Traits) float64 { return t.PhGrowthEffect }},
+}
+
+// traitMetrics returns every numeric organism trait included in metrics,
+// including the ideal value and tolerance for each of the config's fields
+func (m *OrganismManager) traitMetrics() []traitMetric {
+	metrics := append([]traitMetric(nil), baseTraitMetrics...)
+	for i, field := range m.cfg.Fields {
+		i, name := i, c.FieldMetricName(field.Name)
+		metrics = append(metrics,
+			traitMetric{"ideal_" + name, func(t organism.Traits) float64 { return t.FieldTraits[i].Ideal }},
+			traitMetric{name + "_tolerance", func(t organism.Traits) float64 { return t.FieldTraits[i].Tolerance }},
+		)
+	}
+	return metrics
+}
+
+// metricActions returns every action counted in metrics, including the
+// diagonal turns on grids with eight directions
+func (m *OrganismManager) metricActions() []d.Action {
+	actions := d.ChoosableActions(m.cfg.EightDirections)
+	// limit the capacity so appending copies the shared list of actions
+	return append(actions[:len(actions):len(actions)], d.ActSpawn)
+}
+
+// Metrics returns the number of distinct lineages among living organisms, the
+// number of organisms taking each action, and the mean and standard deviation
+// of each organism trait, always in the same order
+func (m *OrganismManager) Metrics() []Metric {
+	m.organismMutex.RLock()
+	defer m.organismMutex.RUnlock()
+
+	traitMetrics := m.traitMetrics()
+	lineages := make(map[int]bool)
+	actions := make(map[d.Action]int)
+	sums := make([]float64, len(traitMetrics))
+	squares := make([]float64, len(traitMetrics))
+	for _, o := range m.organisms {
+		lineages[o.OriginalAncestorID] = true
+		actions[o.Action()]++
+		traits := o.Traits()
+		for i, trait := range traitMetrics {
+			value := trait.value(traits)
+			sums[i] += value
+			squares[i] += value * value
+		}
+	}
+
+	metrics := []Metric{{"lineages", float64(len(lineages))}}
+	for _, action := range m.metricActions() {
+		metrics = append(metrics, Metric{"action_" + metricName(d.Map[action]), float64(actions[action])})
+	}
+	count := float64(len(m.organisms))
+	for i, trait := range traitMetrics {
+		mean, stdDev := 0.0, 0.0
+		if count > 0 {
+			mean = sums[i] / count
+			stdDev = math.Sqrt(math.Max(0, squares[i]/count-mean*mean))
+		}
+		metrics = append(metrics, Metric{trait.name + "_mean", mean}, Metric{trait.name + "_stddev", stdDev})
+	}
+	return metrics
+}
+
+// TotalCreated returns the total number of organisms ever created
+func (m *OrganismManager) TotalCreated() int {
+	return m.totalOrganismsCreated
+}
+
+// metricName converts a display name like "Move Ahead" to "move_ahead"
+func metricName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+}
+
+// TotalValue returns the total value of all food items
+func (m *FoodManager) TotalValue() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	total := 0
+	for _, item := range m.Items {
+		total += item.Value
+	}
+	return total
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
 	c "github.com/Zebbeni/protozoa/config"
 	d "github.com/Zebbeni/protozoa/decision"
+	"github.com/Zebbeni/protozoa/event"
 	"github.com/Zebbeni/protozoa/food"
 	"github.com/Zebbeni/protozoa/organism"
 	"github.com/Zebbeni/protozoa/utils"
@@ -17,7 +19,11 @@ import (
 // OrganismManager contains 2D array of booleans showing if organism present
 type OrganismManager struct {
 	api            organism.API
+	cfg            *c.Globals
+	geometry       *utils.Geometry
 	requestManager RequestManager
+	random         *utils.Random
+	deterministic  bool
 
 	organisms             map[int]*organism.Organism
 	organismIDGrid        [][]int
@@ -25,6 +31,10 @@ type OrganismManager struct {
 
 	organismIds []int
 
+	// shade is the fraction of light blocked from each organism this cycle,
+	// or nil if organisms cast no shade
+	shade map[int]float64
+
 	oldestId         int
 	oldestAge        int
 	mostChildrenId   int
@@ -38,25 +48,39 @@ type OrganismManager struct {
 
 	UpdateDuration, ResolveDuration time.Duration
 
+	// events reported during the current cycle, only recorded while someone
+	// is observing them
+	recordEvents bool
+	events       []event.Event
+	eventMutex   sync.Mutex
+
 	ancestorMutex sync.RWMutex
 	gridMutex     sync.RWMutex
 	organismMutex sync.RWMutex
 }
 
-// NewOrganismManager creates all Organisms and updates grid
-func NewOrganismManager(api organism.API) *OrganismManager {
-	grid := initializeGrid()
+// NewOrganismManager creates all Organisms and updates grid. New random
+// organisms draw their values from the given stream. If deterministic is set,
+// organisms are resolved one at a time in order of ID so that the same stream
+// always produces the same results.
+func NewOrganismManager(api organism.API, random *utils.Random, deterministic bool) *OrganismManager {
+	cfg := api.Config()
+	grid := initializeGrid(cfg.GridUnitsWide, cfg.GridUnitsHigh)
 	organisms := make(map[int]*organism.Organism)
 	manager := &OrganismManager{
 		api:                    api,
+		cfg:                    cfg,
+		geometry:               api.Geometry(),
 		requestManager:         RequestManager{},
+		random:                 random,
+		deterministic:          deterministic,
 		organismIDGrid:         grid,
 		organisms:              organisms,
-		organismIds:            make([]int, 0, c.MaxOrganisms()),
+		organismIds:            make([]int, 0, cfg.MaxOrganisms),
 		originalAncestorColors: make(map[int]color.Color),
 		populationHistory:      make(map[int]map[int]int32),
 	}
-	manager.InitializeOrganisms(c.InitialOrganisms())
+	manager.InitializeOrganisms(cfg.InitialOrganisms)
 	return manager
 }
 
@@ -70,10 +94,11 @@ func (m *OrganismManager) InitializeOrganisms(count int) {
 // chosen action to the organism, the grid, and the environment
 func (m *OrganismManager) Update() {
 	m.requestManager.ClearMaps()
-	m.organismIds = make([]int, 0, c.MaxOrganisms())
+	m.organismIds = make([]int, 0, m.cfg.MaxOrganisms)
 
 	m.resetInterestingStats()
 
+	m.updateShade()
 	m.updateOrganismActions()
 	m.resolveOrganismActions()
 
@@ -83,9 +108,8 @@ func (m *OrganismManager) Update() {
 func (m *OrganismManager) updateOrganismActions() {
 	start := time.Now()
 
-	m.organismMutex.RLock()
-	orgsToUpdate := make(chan int, len(m.organisms))
-	m.organismMutex.RUnlock()
+	ids := m.getOrganismIDs()
+	orgsToUpdate := make(chan int, len(ids))
 
 	numWorkers := 8
 	var wg sync.WaitGroup
@@ -104,7 +128,7 @@ func (m *OrganismManager) updateOrganismActions() {
 		}()
 	}
 
-	for k := range m.organisms {
+	for _, k := range ids {
 		orgsToUpdate <- k
 	}
 	close(orgsToUpdate)
@@ -112,9 +136,38 @@ func (m *OrganismManager) updateOrganismActions() {
 	// wait for all worker threads to call Done()
 	wg.Wait()
 
+	if m.deterministic {
+		m.addRequestsInOrder(ids)
+	}
+
 	m.UpdateDuration = time.Since(start)
 }
 
+// getOrganismIDs returns the IDs of all living organisms, sorted if organisms
+// must be processed in a fixed order
+func (m *OrganismManager) getOrganismIDs() []int {
+	m.organismMutex.RLock()
+	ids := make([]int, 0, len(m.organisms))
+	for id := range m.organisms {
+		ids = append(ids, id)
+	}
+	m.organismMutex.RUnlock()
+
+	if m.deterministic {
+		sort.Ints(ids)
+	}
+	return ids
+}
+
+// addRequestsInOrder adds the requests of all updated organisms in order of ID,
+// so that conflicting requests are always combined the same way
+func (m *OrganismManager) addRequestsInOrder(ids []int) {
+	for _, id := range ids {
+		m.updateRequestMap(m.organisms[id])
+	}
+	m.organismIds = ids
+}
+
 func (m *OrganismManager) resetInterestingStats() {
 	m.oldestId = -1
 	m.oldestAge = -1
@@ -142,6 +195,14 @@ func (m *OrganismManager) updateInterestingStats(o *organism.Organism) {
 func (m *OrganismManager) resolveOrganismActions() {
 	start := time.Now()
 
+	if m.deterministic {
+		for _, id := range m.organismIds {
+			m.resolveOrganismAction(m.organisms[id])
+		}
+		m.ResolveDuration = time.Since(start)
+		return
+	}
+
 	orgsToResolve := make(chan *organism.Organism, len(m.organismIds))
 
 	numWorkers := 8
@@ -172,7 +233,7 @@ func (m *OrganismManager) resolveOrganismActions() {
 // updateHistory updates the population map for all living organisms
 func (m *OrganismManager) updateHistory() {
 	cycle := m.api.Cycle()
-	if cycle%c.PopulationUpdateInterval() != 0 {
+	if cycle%m.cfg.PopulationUpdateInterval != 0 {
 		return
 	}
 
@@ -207,16 +268,18 @@ func (m *OrganismManager) updateRequestMap(o *organism.Organism) {
 
 func (m *OrganismManager) addAttackRequest(o *organism.Organism) {
 	effect := m.calculateAttackEffect(o)
-	target := o.Location.Add(o.Direction)
+	target := m.geometry.Add(o.Location, o.Direction)
 	m.requestManager.AddHealthEffectRequest(target, effect)
+	m.addTargetedEvent(event.Attacked, o, target, -1*effect)
 }
 
 func (m *OrganismManager) addFeedRequest(o *organism.Organism) {
 	// the feed effect constant is negative so multiply by -1 to
 	// get a positive health benefit for the beneficiary organism
 	effect := -1 * m.calculateFeedEffect(o)
-	target := o.Location.Add(o.Direction)
+	target := m.geometry.Add(o.Location, o.Direction)
 	m.requestManager.AddHealthEffectRequest(target, effect)
+	m.addTargetedEvent(event.Fed, o, target, effect)
 }
 
 func (m *OrganismManager) addSpawnRequest(o *organism.Organism) {
@@ -229,19 +292,39 @@ func (m *OrganismManager) addSpawnRequest(o *organism.Organism) {
 // determine the new position required for a move action add 1 to the number of
 // requests for this position in positionRequests
 func (m *OrganismManager) addMoveRequest(o *organism.Organism) {
-	target := o.Location.Add(o.Direction)
+	target := m.geometry.Add(o.Location, o.Direction)
 
+	if m.geometry.CutsCorner(o.Location, o.Direction) {
+		return
+	}
 	// Only make request if empty, to avoid complications resolving it later
 	if empty := m.isGridLocationEmpty(target); empty {
 		m.requestManager.AddPositionRequest(target, o.ID)
+		if o.Direction.IsDiagonal() {
+			m.requestManager.AddCrossingRequest(m.getCrossing(o), o.ID)
+		}
 	}
 }
 
+// getCrossing returns the top-left point of the 2x2 square an organism's
+// diagonal move crosses. Moves along both diagonals of a square share the same
+// crossing, so only one of them may go ahead.
+func (m *OrganismManager) getCrossing(o *organism.Organism) utils.Point {
+	offset := utils.Point{}
+	if o.Direction.X < 0 {
+		offset.X = -1
+	}
+	if o.Direction.Y < 0 {
+		offset.Y = -1
+	}
+	return m.geometry.Add(o.Location, offset)
+}
+
 // calculate the amount of food the given organism requests to eat at a target
 // location. Add this to the food item stored for this location representing
 // the total eat requests made here.
 func (m *OrganismManager) addFoodRequest(o *organism.Organism) {
-	target := o.Location.Add(o.Direction)
+	target := m.geometry.Add(o.Location, o.Direction)
 	value := int(math.Ceil(m.calculateValueToEat(o, target)))
 	m.requestManager.AddFoodRequest(target, value)
 }
@@ -277,6 +360,10 @@ func (m *OrganismManager) updateOrganismAction(o *organism.Organism) {
 	}
 	o.UpdateStats()
 	o.UpdateAction()
+	if m.deterministic {
+		// requests are added after all actions are chosen, in a fixed order
+		return
+	}
 	m.updateRequestMap(o)
 	m.addToOrganismIds(o)
 }
@@ -291,9 +378,13 @@ func (m *OrganismManager) resolveOrganismAction(o *organism.Organism) {
 	if o == nil || o.Age == 0 {
 		return
 	}
-	m.applyCycleHealthChanges(o)
+	cause := m.applyCycleHealthChanges(o)
+	if o.Health > 0 {
+		// any death from here on is caused by the organism's own action
+		cause = event.CauseExertion
+	}
 	m.applyAction(o)
-	m.removeIfDead(o)
+	m.removeIfDead(o, cause)
 	m.updateInterestingStats(o)
 }
 
@@ -304,11 +395,25 @@ func (m *OrganismManager) resolveOrganismAction(o *organism.Organism) {
 func (m *OrganismManager) SpawnRandomOrganism() {
 	if spawnPoint, found := m.getRandomSpawnLocation(); found {
 		id := m.generateId()
-		o := organism.NewRandom(id, spawnPoint, m.api)
+		o := organism.NewRandom(id, spawnPoint, m.api, m.random.NewStream())
 		m.registerNewOrganism(o, id)
+		m.addSpawnedEvent(o, -1)
 	}
 }
 
+// SpawnRandomOrganismAt creates a new organism with random traits at a given
+// point and returns its ID, or false if the point is not empty
+func (m *OrganismManager) SpawnRandomOrganismAt(point utils.Point) (int, bool) {
+	if !m.isGridLocationEmpty(point) {
+		return 0, false
+	}
+	id := m.generateId()
+	o := organism.NewRandom(id, point, m.api, m.random.NewStream())
+	m.registerNewOrganism(o, id)
+	m.addSpawnedEvent(o, -1)
+	return id, true
+}
+
 // SpawnChildOrganism creates a new organism near an existing 'parent' organism
 // with a copy of its parent's node library. (No organism created if no room or
 // if more than 1 organism made a request to spawn and/or move into the desired
@@ -326,6 +431,7 @@ func (m *OrganismManager) SpawnChildOrganism(parent *organism.Organism) bool {
 	o := parent.NewChild(id, spawnPoint, m.api)
 	m.registerNewOrganism(o, id)
 	m.addToOriginalAncestors(parent)
+	m.addSpawnedEvent(o, parent.ID)
 	return true
 }
 
@@ -373,19 +479,27 @@ func (m *OrganismManager) addToOriginalAncestors(o *organism.Organism) {
 
 // returns a random point and whether it is empty
 func (m *OrganismManager) getRandomSpawnLocation() (utils.Point, bool) {
-	point := utils.GetRandomPoint(c.GridUnitsWide(), c.GridUnitsHigh())
+	point := utils.GetRandomPoint(m.random.Rand, m.geometry.Width(), m.geometry.Height())
 	isEmpty := m.isGridLocationEmpty(point)
 	return point, isEmpty
 }
 
+// getChildSpawnLocation returns the first empty point next to a parent,
+// turning left from the direction it faces, and whether one was found. On
+// grids with eight directions it turns 45 degrees at a time, skipping points
+// it can't reach without cutting the corner of a wall.
 func (m *OrganismManager) getChildSpawnLocation(parent *organism.Organism) (utils.Point, bool) {
 	var point utils.Point
 	direction := parent.Direction
-	for i := 0; i < 4; i++ {
-		direction = direction.Left()
-		point = parent.Location.Add(direction)
+	for i := 0; i < len(m.geometry.Directions()); i++ {
+		if m.geometry.HasEightDirections() {
+			direction = direction.HalfLeft()
+		} else {
+			direction = direction.Left()
+		}
+		point = m.geometry.Add(parent.Location, direction)
 
-		empty := m.isGridLocationEmpty(point)
+		empty := m.isGridLocationEmpty(point) && !m.geometry.CutsCorner(parent.Location, direction)
 		if empty {
 			return point, true
 		}
@@ -394,13 +508,13 @@ func (m *OrganismManager) getChildSpawnLocation(parent *organism.Organism) (util
 	return point, false
 }
 
-func initializeGrid() [][]int {
-	grid := make([][]int, c.GridUnitsWide())
-	for r := 0; r < c.GridUnitsWide(); r++ {
-		grid[r] = make([]int, c.GridUnitsHigh())
+func initializeGrid(width, height int) [][]int {
+	grid := make([][]int, width)
+	for r := 0; r < width; r++ {
+		grid[r] = make([]int, height)
 	}
-	for x := 0; x < c.GridUnitsWide(); x++ {
-		for y := 0; y < c.GridUnitsHigh(); y++ {
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
 			grid[x][y] = -1
 		}
 	}
@@ -408,7 +522,7 @@ func initializeGrid() [][]int {
 }
 
 func (m *OrganismManager) isGridLocationEmpty(point utils.Point) bool {
-	return !point.IsWall() && !m.isFoodAtLocation(point) && !m.isOrganismAtLocation(point)
+	return !m.geometry.IsWall(point) && !m.isFoodAtLocation(point) && !m.isOrganismAtLocation(point)
 }
 
 func (m *OrganismManager) isFoodAtLocation(point utils.Point) bool {
@@ -418,11 +532,8 @@ func (m *OrganismManager) isFoodAtLocation(point utils.Point) bool {
 }
 
 func (m *OrganismManager) isOrganismAtLocation(point utils.Point) bool {
-	m.gridMutex.RLock()
-	id := m.organismIDGrid[point.X][point.Y]
-	m.gridMutex.RUnlock()
-
-	return id != -1
+	_, exists := m.getOrganismIDAt(point)
+	return exists
 }
 
 func (m *OrganismManager) getOrganismAt(point utils.Point) *organism.Organism {
@@ -438,6 +549,10 @@ func (m *OrganismManager) getOrganismAt(point utils.Point) *organism.Organism {
 }
 
 func (m *OrganismManager) getOrganismIDAt(point utils.Point) (int, bool) {
+	// points beyond the edge of a bounded grid never hold organisms
+	if !m.geometry.InBounds(point) {
+		return -1, false
+	}
 	m.gridMutex.RLock()
 	id := m.organismIDGrid[point.X][point.Y]
 	m.gridMutex.RUnlock()
@@ -522,6 +637,24 @@ func (m *OrganismManager) OrganismCount() int {
 	return len(m.organisms)
 }
 
+// LargestLineage returns the original ancestor with the most living
+// descendants and the number of descendants it has
+func (m *OrganismManager) LargestLineage() (int, int) {
+	m.organismMutex.RLock()
+	defer m.organismMutex.RUnlock()
+
+	counts := make(map[int]int)
+	largestID, largestCount := -1, 0
+	for _, o := range m.organisms {
+		counts[o.OriginalAncestorID]++
+		count := counts[o.OriginalAncestorID]
+		if count > largestCount || (count == largestCount && o.OriginalAncestorID < largestID) {
+			largestID, largestCount = o.OriginalAncestorID, count
+		}
+	}
+	return largestID, largestCount
+}
+
 // DeadCount returns the total number of organisms that have died in the simulation
 func (m *OrganismManager) DeadCount() int {
 	return m.totalOrganismsCreated - len(m.organisms)
@@ -550,33 +683,70 @@ func (m *OrganismManager) applyAction(o *organism.Organism) {
 	case d.ActTurnRight:
 		m.applyRightTurn(o)
 		break
+	case d.ActTurnHalfLeft:
+		m.applyHalfLeftTurn(o)
+		break
+	case d.ActTurnHalfRight:
+		m.applyHalfRightTurn(o)
+		break
 	case d.ActSpawn:
 		m.applySpawn(o)
 		break
 	}
 }
 
-func (m *OrganismManager) applyCycleHealthChanges(o *organism.Organism) {
-	decisionsEffect := c.HealthChangePerDecisionTreeNode() * float64(o.GetCurrentDecisionTreeLength())
+// applyCycleHealthChanges applies the health changes an organism undergoes on
+// every cycle and returns whichever did the most harm, as the cause of death
+// if the organism's health has fallen to zero
+func (m *OrganismManager) applyCycleHealthChanges(o *organism.Organism) event.Cause {
+	decisionsEffect := m.cfg.HealthChangePerDecisionTreeNode * float64(o.GetCurrentDecisionTreeLength())
 	phEffect := 0.0
 	// Subtract health if organism is too far away from its ideal ph
 	phDist := math.Abs(o.Traits().IdealPh - m.api.GetPhAtPoint(o.Location))
 	if phDist > o.Traits().PhTolerance {
-		phEffect = (phDist - o.Traits().PhTolerance) * c.HealthChangePerUnhealthyPh()
+		phEffect = (phDist - o.Traits().PhTolerance) * m.cfg.HealthChangePerCycleUnhealthyPh
 	}
+	fieldsEffect := m.calculateFieldsEffect(o)
 	// Add effects due to feeding and/or attack (not related to organism size)
 	healthEffects := m.requestManager.GetHealthEffects(o.Location)
-	m.applyHealthChange(o, o.Size*(decisionsEffect+phEffect)+healthEffects)
+	m.applyHealthChange(o, o.Size*(decisionsEffect+phEffect+fieldsEffect)+healthEffects)
+	m.addTargetedEventsFor(o)
+
+	cause, worst := event.CauseUpkeep, decisionsEffect*o.Size
+	if phEffect*o.Size < worst {
+		cause, worst = event.CausePh, phEffect*o.Size
+	}
+	if fieldsEffect*o.Size < worst {
+		cause, worst = event.CauseEnvironment, fieldsEffect*o.Size
+	}
+	if healthEffects < worst {
+		cause = event.CauseAttack
+	}
+	return cause
+}
+
+// calculateFieldsEffect returns the health change, as a percent of organism
+// size, from every field whose value is too far from the organism's ideal
+func (m *OrganismManager) calculateFieldsEffect(o *organism.Organism) float64 {
+	effect := 0.0
+	for i, trait := range o.Traits().FieldTraits {
+		dist := math.Abs(trait.Ideal - m.api.GetFieldAtPoint(i, o.Location))
+		if dist > trait.Tolerance {
+			effect += (dist - trait.Tolerance) * m.cfg.Fields[i].HealthChangePerUnhealthy
+		}
+	}
+	return effect
 }
 
 // add a positive health change if organism attempts chemosynthesis in a
-// favorable ph environment
+// favorable ph environment, scaled by the light reaching it
 func (m *OrganismManager) applyChemosynthesis(o *organism.Organism) {
 	ph := m.api.GetPhAtPoint(o.Location)
 	ideal := o.Traits().IdealPh
 	tolerance := o.Traits().PhTolerance
 	if math.Abs(ideal-ph) < tolerance {
-		m.applyHealthChange(o, c.HealthChangeFromChemosynthesis()*o.Size)
+		light := m.getLight(o.ID, o.Location)
+		m.applyHealthChange(o, m.cfg.HealthChangeFromChemosynthesis*o.Size*light)
 	}
 }
 
@@ -592,14 +762,14 @@ func (m *OrganismManager) applyHealthChange(o *organism.Organism, amount float64
 
 func (m *OrganismManager) applyAttack(o *organism.Organism) {
 	m.addUpdatedPoint(o.Location)
-	m.applyHealthChange(o, c.HealthChangeFromAttacking()*o.Size)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromAttacking*o.Size)
 }
 
 func (m *OrganismManager) calculateAttackEffect(o *organism.Organism) float64 {
-	return c.HealthChangeInflictedByAttack() * o.Size
+	return m.cfg.HealthChangeInflictedByAttack * o.Size
 }
 
-func (m *OrganismManager) removeIfDead(o *organism.Organism) bool {
+func (m *OrganismManager) removeIfDead(o *organism.Organism, cause event.Cause) bool {
 	if o.Health > 0.0 {
 		return false
 	}
@@ -615,6 +785,14 @@ func (m *OrganismManager) removeIfDead(o *organism.Organism) bool {
 
 	m.api.AddFoodAtPoint(o.Location, int(o.Size))
 	m.addUpdatedPoint(o.Location)
+	m.addEvent(event.Event{
+		Type:       event.Died,
+		OrganismID: o.ID,
+		ParentID:   -1,
+		TargetID:   -1,
+		Location:   o.Location,
+		Cause:      cause,
+	})
 
 	return true
 }
@@ -627,16 +805,16 @@ func (m *OrganismManager) applySpawn(o *organism.Organism) {
 }
 
 func (m *OrganismManager) applyFeed(o *organism.Organism) {
-	m.applyHealthChange(o, c.HealthChangeFromFeeding()*o.Size)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromFeeding*o.Size)
 }
 
 func (m *OrganismManager) calculateFeedEffect(o *organism.Organism) float64 {
-	return c.HealthChangeFromFeeding() * o.Size
+	return m.cfg.HealthChangeFromFeeding * o.Size
 }
 
 func (m *OrganismManager) applyEat(o *organism.Organism) {
-	m.applyHealthChange(o, c.HealthChangeFromEatingAttempt()*o.Size)
-	target := o.Location.Add(o.Direction)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromEatingAttempt*o.Size)
+	target := m.geometry.Add(o.Location, o.Direction)
 
 	// apply health change but don't delete food until all eat requests have been
 	// processed. This may result in more total food being consumed by nearby organisms
@@ -645,6 +823,17 @@ func (m *OrganismManager) applyEat(o *organism.Organism) {
 	amountToEat := m.calculateValueToEat(o, target)
 	m.api.RemoveFoodAtPoint(target, int(math.Ceil(amountToEat)))
 	m.applyHealthChange(o, amountToEat)
+	if amountToEat > 0 {
+		m.addEvent(event.Event{
+			Type:       event.Ate,
+			OrganismID: o.ID,
+			ParentID:   -1,
+			TargetID:   -1,
+			Location:   o.Location,
+			Target:     target,
+			Amount:     amountToEat,
+		})
+	}
 }
 
 func (m *OrganismManager) calculateValueToEat(o *organism.Organism, target utils.Point) float64 {
@@ -656,12 +845,15 @@ func (m *OrganismManager) calculateValueToEat(o *organism.Organism, target utils
 }
 
 func (m *OrganismManager) applyMove(o *organism.Organism) {
-	m.applyHealthChange(o, c.HealthChangeFromMoving()*o.Size)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromMoving*o.Size)
 
-	targetPoint := o.Location.Add(o.Direction)
+	targetPoint := m.geometry.Add(o.Location, o.Direction)
 	if m.isMatchingPositionRequest(targetPoint, o.ID) == false {
 		return
 	}
+	if o.Direction.IsDiagonal() && m.requestManager.GetCrossingRequest(m.getCrossing(o)) != o.ID {
+		return
+	}
 
 	m.addUpdatedPoint(o.Location)
 	m.addUpdatedPoint(targetPoint)
@@ -673,21 +865,107 @@ func (m *OrganismManager) applyMove(o *organism.Organism) {
 	m.organismIDGrid[targetPoint.X][targetPoint.Y] = o.ID
 	m.gridMutex.Unlock()
 
+	m.addEvent(event.Event{
+		Type:       event.Moved,
+		OrganismID: o.ID,
+		ParentID:   -1,
+		TargetID:   -1,
+		Location:   targetPoint,
+		From:       o.Location,
+	})
 	o.Location = targetPoint
 }
 
 func (m *OrganismManager) applyRightTurn(o *organism.Organism) {
-	m.applyHealthChange(o, c.HealthChangeFromTurning()*o.Size)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromTurning*o.Size)
 
 	o.Direction = o.Direction.Right()
 }
 
 func (m *OrganismManager) applyLeftTurn(o *organism.Organism) {
-	m.applyHealthChange(o, c.HealthChangeFromTurning()*o.Size)
+	m.applyHealthChange(o, m.cfg.HealthChangeFromTurning*o.Size)
 
 	o.Direction = o.Direction.Left()
 }
 
+func (m *OrganismManager) applyHalfRightTurn(o *organism.Organism) {
+	m.applyHealthChange(o, m.cfg.HealthChangeFromTurning*o.Size)
+
+	o.Direction = o.Direction.HalfRight()
+}
+
+func (m *OrganismManager) applyHalfLeftTurn(o *organism.Organism) {
+	m.applyHealthChange(o, m.cfg.HealthChangeFromTurning*o.Size)
+
+	o.Direction = o.Direction.HalfLeft()
+}
+
+// RecordEvents sets whether events should be recorded for observers
+func (m *OrganismManager) RecordEvents(record bool) {
+	m.recordEvents = record
+}
+
+// TakeEvents returns all events recorded since the last call and clears them
+func (m *OrganismManager) TakeEvents() []event.Event {
+	m.eventMutex.Lock()
+	defer m.eventMutex.Unlock()
+
+	events := m.events
+	m.events = nil
+	return events
+}
+
+func (m *OrganismManager) addEvent(e event.Event) {
+	if !m.recordEvents {
+		return
+	}
+	e.Cycle = m.api.Cycle()
+
+	m.eventMutex.Lock()
+	m.events = append(m.events, e)
+	m.eventMutex.Unlock()
+}
+
+func (m *OrganismManager) addSpawnedEvent(o *organism.Organism, parentID int) {
+	m.addEvent(event.Event{
+		Type:       event.Spawned,
+		OrganismID: o.ID,
+		ParentID:   parentID,
+		TargetID:   -1,
+		Location:   o.Location,
+	})
+}
+
+// addTargetedEvent records an event for an attack or feed directed at a
+// target point. Its TargetID is only filled in once the health change is
+// applied to whichever organism is at the target by then.
+func (m *OrganismManager) addTargetedEvent(eventType event.Type, o *organism.Organism, target utils.Point, amount float64) {
+	if !m.recordEvents {
+		return
+	}
+	m.requestManager.AddTargetedEvent(target, event.Event{
+		Type:       eventType,
+		OrganismID: o.ID,
+		ParentID:   -1,
+		TargetID:   -1,
+		Location:   o.Location,
+		Target:     target,
+		Amount:     amount,
+	})
+}
+
+// addTargetedEventsFor adds the events for every attack and feed whose health
+// change has just been applied to o
+func (m *OrganismManager) addTargetedEventsFor(o *organism.Organism) {
+	if !m.recordEvents {
+		return
+	}
+	for _, e := range m.requestManager.GetTargetedEvents(o.Location) {
+		e.TargetID = o.ID
+		m.addEvent(e)
+	}
+}
+
 // GetAllOrganismInfo returns a map of all organisms' Info
 func (m *OrganismManager) GetAllOrganismInfo() map[int]*organism.Info {
 	infoMap := make(map[int]*organism.Info)
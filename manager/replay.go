@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+
+	"github.com/Zebbeni/protozoa/food"
+	"github.com/Zebbeni/protozoa/organism"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// Ancestor is an original ancestor and the color its descendants are drawn in
+type Ancestor struct {
+	ID    int
+	Color colorful.Color
+}
+
+// OrganismChanges contains everything that changed about the organisms tracked
+// by an OrganismManager during one cycle
+type OrganismChanges struct {
+	Spawned   []organism.State
+	Died      []int
+	Organisms []organism.Changes
+	Ancestors []Ancestor
+}
+
+// Changes returns the current Changes of all living organisms, ordered by ID
+func (m *OrganismManager) Changes() []organism.Changes {
+	m.organismMutex.RLock()
+	changes := make([]organism.Changes, 0, len(m.organisms))
+	for _, o := range m.organisms {
+		changes = append(changes, o.Changes())
+	}
+	m.organismMutex.RUnlock()
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// OrganismState returns the full State of a living organism and whether it
+// was found
+func (m *OrganismManager) OrganismState(id int) (organism.State, bool) {
+	m.organismMutex.RLock()
+	defer m.organismMutex.RUnlock()
+
+	if o, found := m.organisms[id]; found {
+		return o.State(), true
+	}
+	return organism.State{}, false
+}
+
+// AncestorsSince returns all original ancestors added after the first count
+func (m *OrganismManager) AncestorsSince(count int) []Ancestor {
+	m.ancestorMutex.RLock()
+	defer m.ancestorMutex.RUnlock()
+
+	if count >= len(m.originalAncestors) {
+		return nil
+	}
+	ancestors := make([]Ancestor, 0, len(m.originalAncestors)-count)
+	for _, id := range m.originalAncestors[count:] {
+		ancestors = append(ancestors, Ancestor{ID: id, Color: toColorful(m.originalAncestorColors[id])})
+	}
+	return ancestors
+}
+
+// ApplyChanges updates all organisms to match previously-recorded changes
+// without choosing or resolving any actions
+func (m *OrganismManager) ApplyChanges(changes OrganismChanges) error {
+	for _, id := range changes.Died {
+		o, found := m.organisms[id]
+		if !found {
+			return fmt.Errorf("organism %d died but does not exist", id)
+		}
+		m.organismIDGrid[o.X()][o.Y()] = -1
+		delete(m.organisms, id)
+		m.addUpdatedPoint(o.Location)
+	}
+
+	// clear every changed organism from the grid before placing any of them,
+	// so organisms moving into each other's previous locations aren't lost
+	moved := make([]*organism.Organism, 0, len(changes.Organisms))
+	for _, c := range changes.Organisms {
+		o, found := m.organisms[c.ID]
+		if !found {
+			return fmt.Errorf("organism %d changed but does not exist", c.ID)
+		}
+		if o.Size != c.Size || o.Action() != c.Action || o.Location != c.Location {
+			m.addUpdatedPoint(o.Location)
+			m.addUpdatedPoint(c.Location)
+		}
+		if o.Location != c.Location {
+			m.organismIDGrid[o.X()][o.Y()] = -1
+			moved = append(moved, o)
+		}
+		o.ApplyChanges(c)
+	}
+	for _, o := range moved {
+		m.organismIDGrid[o.X()][o.Y()] = o.ID
+	}
+
+	for _, state := range changes.Spawned {
+		o, err := organism.FromState(state, m.api)
+		if err != nil {
+			return fmt.Errorf("failed to spawn organism %d: %w", state.ID, err)
+		}
+		m.registerNewOrganism(o, o.ID)
+		if o.ID > m.totalOrganismsCreated {
+			m.totalOrganismsCreated = o.ID
+		}
+	}
+
+	for _, ancestor := range changes.Ancestors {
+		m.originalAncestorColors[ancestor.ID] = ancestor.Color
+		m.originalAncestors = append(m.originalAncestors, ancestor.ID)
+	}
+
+	m.resetInterestingStats()
+	for _, o := range m.organisms {
+		m.updateInterestingStats(o)
+	}
+	m.updateHistory()
+	return nil
+}
+
+// SetFoodAtPoint replaces any food at a given point with a new value, removing
+// it if the value is zero
+func (m *FoodManager) SetFoodAtPoint(point utils.Point, value int) {
+	pointString := point.ToString()
+
+	m.mutex.Lock()
+	if value <= 0 {
+		delete(m.Items, pointString)
+	} else {
+		m.Items[pointString] = food.NewItem(point, value)
+	}
+	m.mutex.Unlock()
+
+	m.addUpdatedPoint(point)
+}
+
+// SetPhAtPoint replaces the pH at a given point
+func (m *EnvironmentManager) SetPhAtPoint(point utils.Point, ph float64) {
+	m.setCurrentPh(point, ph)
+	m.addUpdatedPoint(point)
+}
+
+// SetAveragePh replaces the average pH of the environment
+func (m *EnvironmentManager) SetAveragePh(averagePh float64) {
+	m.averagePh = averagePh
+}
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"github.com/Zebbeni/protozoa/event"
 	"github.com/Zebbeni/protozoa/food"
 	"github.com/Zebbeni/protozoa/utils"
 	"sync"
@@ -9,9 +10,11 @@ import (
 // RequestManager manages access maps that keep track of overlapping or
 // conflicting requests placed by organisms due to concurrent action updates
 type RequestManager struct {
-	positionRequests     map[string]int       // the lowest id of an organism requesting to move or spawn at a point
-	foodRequests         map[string]food.Item // the amount of food eaten at a given point
-	healthEffectRequests map[string]float64   // the total damage + healing effects at a given location
+	positionRequests     map[string]int           // the lowest id of an organism requesting to move or spawn at a point
+	foodRequests         map[string]food.Item     // the amount of food eaten at a given point
+	healthEffectRequests map[string]float64       // the total damage + healing effects at a given location
+	targetedEvents       map[string][]event.Event // events for the attacks and feeds directed at a given location
+	crossingRequests     map[string]int           // the id of the organism allowed to move diagonally across a 2x2 square
 
 	mutex sync.Mutex
 }
@@ -23,6 +26,8 @@ func (m *RequestManager) ClearMaps() {
 	m.positionRequests = make(map[string]int)
 	m.foodRequests = make(map[string]food.Item)
 	m.healthEffectRequests = make(map[string]float64)
+	m.targetedEvents = make(map[string][]event.Event)
+	m.crossingRequests = make(map[string]int)
 }
 
 func (m *RequestManager) GetPositionRequest(p utils.Point) int {
@@ -32,6 +37,15 @@ func (m *RequestManager) GetPositionRequest(p utils.Point) int {
 	return m.positionRequests[p.ToString()]
 }
 
+// GetCrossingRequest returns the id of the organism allowed to move diagonally
+// across the 2x2 square whose top-left point is p
+func (m *RequestManager) GetCrossingRequest(p utils.Point) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.crossingRequests[p.ToString()]
+}
+
 func (m *RequestManager) GetFoodRequests(p utils.Point) food.Item {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -46,6 +60,15 @@ func (m *RequestManager) GetHealthEffects(p utils.Point) float64 {
 	return m.healthEffectRequests[p.ToString()]
 }
 
+// GetTargetedEvents returns the events for every attack and feed directed at a
+// given location
+func (m *RequestManager) GetTargetedEvents(p utils.Point) []event.Event {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.targetedEvents[p.ToString()]
+}
+
 func (m *RequestManager) AddPositionRequest(p utils.Point, id int) {
 	pString := p.ToString()
 
@@ -56,6 +79,18 @@ func (m *RequestManager) AddPositionRequest(p utils.Point, id int) {
 	m.mutex.Unlock()
 }
 
+// AddCrossingRequest requests a diagonal move across the 2x2 square whose
+// top-left point is p. Like position requests, the highest id wins.
+func (m *RequestManager) AddCrossingRequest(p utils.Point, id int) {
+	pString := p.ToString()
+
+	m.mutex.Lock()
+	if id > m.crossingRequests[pString] {
+		m.crossingRequests[pString] = id
+	}
+	m.mutex.Unlock()
+}
+
 func (m *RequestManager) AddFoodRequest(p utils.Point, value int) {
 	pString := p.ToString()
 	m.mutex.Lock()
@@ -72,3 +107,10 @@ func (m *RequestManager) AddHealthEffectRequest(p utils.Point, v float64) {
 	m.healthEffectRequests[pString] += v
 	m.mutex.Unlock()
 }
+
+func (m *RequestManager) AddTargetedEvent(p utils.Point, e event.Event) {
+	pString := p.ToString()
+	m.mutex.Lock()
+	m.targetedEvents[pString] = append(m.targetedEvents[pString], e)
+	m.mutex.Unlock()
+}
@@ -0,0 +1,40 @@
+package manager
+
+import (
+	"math"
+
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// shadeOffsets are the locations around an organism that it shades
+var shadeOffsets = []utils.Point{
+	{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+	{X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+}
+
+// updateShade finds the fraction of light blocked from each organism by the
+// organisms around it, each blocking up to MaxShade in proportion to its size.
+// Shade is found before any organism acts, so that it doesn't depend on the
+// order organisms are resolved in.
+func (m *OrganismManager) updateShade() {
+	if m.cfg.MaxShade <= 0 {
+		m.shade = nil
+		return
+	}
+	m.shade = make(map[int]float64, len(m.organisms))
+	for id, o := range m.organisms {
+		shade := 0.0
+		for _, offset := range shadeOffsets {
+			if neighbor := m.getOrganismAt(m.geometry.Add(o.Location, offset)); neighbor != nil {
+				shade += m.cfg.MaxShade * neighbor.Size / m.cfg.MaximumMaxSize
+			}
+		}
+		m.shade[id] = math.Min(shade, 1)
+	}
+}
+
+// getLight returns the light reaching an organism, after shade
+func (m *OrganismManager) getLight(id int, point utils.Point) float64 {
+	return m.api.GetLightAtPoint(point) * (1 - m.shade[id])
+}
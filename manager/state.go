@@ -0,0 +1,228 @@
+package manager
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+
+	"github.com/Zebbeni/protozoa/environment"
+	"github.com/Zebbeni/protozoa/food"
+	"github.com/Zebbeni/protozoa/organism"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// OrganismManagerState contains everything needed to save and restore the
+// organisms and population history tracked by an OrganismManager
+type OrganismManagerState struct {
+	Organisms              []organism.State
+	TotalOrganismsCreated  int
+	OriginalAncestors      []int
+	OriginalAncestorColors map[int]colorful.Color
+	PopulationHistory      map[int]map[int]int32
+	RandomState            uint64
+}
+
+// FoodManagerState contains all food Items tracked by a FoodManager
+type FoodManagerState struct {
+	Items       []food.Item
+	RandomState uint64
+}
+
+// EnvironmentManagerState contains the pH maps and vents tracked by an
+// EnvironmentManager
+type EnvironmentManagerState struct {
+	CurrentPhMap  [][]float64
+	PreviousPhMap [][]float64
+	AveragePh     float64
+	Vents         []environment.Vent
+	Fields        []FieldState
+}
+
+// FieldState contains the values of one of the environment's other fields
+type FieldState struct {
+	Current  [][]float64
+	Previous [][]float64
+	Average  float64
+}
+
+// State returns the full saveable state of the OrganismManager
+func (m *OrganismManager) State() OrganismManagerState {
+	m.organismMutex.RLock()
+	organisms := make([]organism.State, 0, len(m.organisms))
+	for _, o := range m.organisms {
+		organisms = append(organisms, o.State())
+	}
+	m.organismMutex.RUnlock()
+	sort.Slice(organisms, func(i, j int) bool {
+		return organisms[i].ID < organisms[j].ID
+	})
+
+	m.ancestorMutex.RLock()
+	ancestors := make([]int, len(m.originalAncestors))
+	copy(ancestors, m.originalAncestors)
+	ancestorColors := make(map[int]colorful.Color, len(m.originalAncestorColors))
+	for id, col := range m.originalAncestorColors {
+		ancestorColors[id] = toColorful(col)
+	}
+	m.ancestorMutex.RUnlock()
+
+	return OrganismManagerState{
+		Organisms:              organisms,
+		TotalOrganismsCreated:  m.totalOrganismsCreated,
+		OriginalAncestors:      ancestors,
+		OriginalAncestorColors: ancestorColors,
+		PopulationHistory:      m.populationHistory,
+		RandomState:            m.random.State(),
+	}
+}
+
+// RestoreOrganismManager creates an OrganismManager from a previously-saved
+// state instead of spawning new organisms
+func RestoreOrganismManager(api organism.API, state OrganismManagerState, deterministic bool) (*OrganismManager, error) {
+	cfg := api.Config()
+	manager := &OrganismManager{
+		api:                    api,
+		cfg:                    cfg,
+		geometry:               api.Geometry(),
+		requestManager:         RequestManager{},
+		random:                 utils.RandomFromState(state.RandomState),
+		deterministic:          deterministic,
+		organismIDGrid:         initializeGrid(cfg.GridUnitsWide, cfg.GridUnitsHigh),
+		organisms:              make(map[int]*organism.Organism),
+		organismIds:            make([]int, 0, cfg.MaxOrganisms),
+		totalOrganismsCreated:  state.TotalOrganismsCreated,
+		originalAncestors:      state.OriginalAncestors,
+		originalAncestorColors: make(map[int]color.Color),
+		populationHistory:      state.PopulationHistory,
+	}
+	if manager.populationHistory == nil {
+		manager.populationHistory = make(map[int]map[int]int32)
+	}
+	for id, col := range state.OriginalAncestorColors {
+		manager.originalAncestorColors[id] = col
+	}
+	for _, organismState := range state.Organisms {
+		o, err := organism.FromState(organismState, api)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore organism %d: %w", organismState.ID, err)
+		}
+		if !o.Location.InBounds(cfg.GridUnitsWide, cfg.GridUnitsHigh) {
+			return nil, fmt.Errorf("organism %d is outside the grid at %v", o.ID, o.Location)
+		}
+		manager.registerNewOrganism(o, o.ID)
+	}
+	manager.resetInterestingStats()
+	return manager, nil
+}
+
+// State returns the full saveable state of the FoodManager
+func (m *FoodManager) State() FoodManagerState {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	items := make([]food.Item, 0, len(m.Items))
+	for _, item := range m.Items {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Point.X != items[j].Point.X {
+			return items[i].Point.X < items[j].Point.X
+		}
+		return items[i].Point.Y < items[j].Point.Y
+	})
+	return FoodManagerState{Items: items, RandomState: m.random.State()}
+}
+
+// RestoreFoodManager creates a FoodManager from a previously-saved state
+// instead of adding initial food
+func RestoreFoodManager(api food.API, state FoodManagerState) *FoodManager {
+	m := &FoodManager{
+		api:           api,
+		Items:         make(map[string]*food.Item),
+		isInitialized: true,
+		cfg:           api.Config(),
+		geometry:      api.Geometry(),
+		random:        utils.RandomFromState(state.RandomState),
+	}
+	for _, item := range state.Items {
+		m.Items[item.Point.ToString()] = food.NewItem(item.Point, item.Value)
+	}
+	return m
+}
+
+// State returns the full saveable state of the EnvironmentManager
+func (m *EnvironmentManager) State() EnvironmentManagerState {
+	fields := make([]FieldState, len(m.fields))
+	for i, f := range m.fields {
+		fields[i] = FieldState{Current: f.current, Previous: f.previous, Average: f.average}
+	}
+	return EnvironmentManagerState{
+		CurrentPhMap:  m.currentPhMap,
+		PreviousPhMap: m.previousPhMap,
+		AveragePh:     m.averagePh,
+		Vents:         m.vents,
+		Fields:        fields,
+	}
+}
+
+// RestoreEnvironmentManager creates an EnvironmentManager from a
+// previously-saved state instead of initializing a new pH map
+func RestoreEnvironmentManager(api environment.API, state EnvironmentManagerState) (*EnvironmentManager, error) {
+	cfg := api.Config()
+	if err := checkMapSize(state.CurrentPhMap, cfg.GridUnitsWide, cfg.GridUnitsHigh); err != nil {
+		return nil, fmt.Errorf("invalid current pH map: %w", err)
+	}
+	if err := checkMapSize(state.PreviousPhMap, cfg.GridUnitsWide, cfg.GridUnitsHigh); err != nil {
+		return nil, fmt.Errorf("invalid previous pH map: %w", err)
+	}
+	if len(state.Fields) != len(cfg.Fields) {
+		return nil, fmt.Errorf("expected %d fields, found %d", len(cfg.Fields), len(state.Fields))
+	}
+	fields := make([]fieldMap, len(state.Fields))
+	for i, f := range state.Fields {
+		if err := checkMapSize(f.Current, cfg.GridUnitsWide, cfg.GridUnitsHigh); err != nil {
+			return nil, fmt.Errorf("invalid current %s map: %w", cfg.Fields[i].Name, err)
+		}
+		if err := checkMapSize(f.Previous, cfg.GridUnitsWide, cfg.GridUnitsHigh); err != nil {
+			return nil, fmt.Errorf("invalid previous %s map: %w", cfg.Fields[i].Name, err)
+		}
+		fields[i] = fieldMap{current: f.Current, previous: f.Previous, average: f.Average}
+	}
+	m := &EnvironmentManager{
+		api:           api,
+		cfg:           cfg,
+		geometry:      api.Geometry(),
+		currentPhMap:  state.CurrentPhMap,
+		previousPhMap: state.PreviousPhMap,
+		averagePh:     state.AveragePh,
+		vents:         state.Vents,
+		fields:        fields,
+	}
+	m.initializeLight()
+	return m, nil
+}
+
+// checkMapSize returns an error if a 2D map does not match the grid dimensions
+func checkMapSize(values [][]float64, width, height int) error {
+	if len(values) != width {
+		return fmt.Errorf("expected %d columns, found %d", width, len(values))
+	}
+	for x := range values {
+		if len(values[x]) != height {
+			return fmt.Errorf("expected %d rows in column %d, found %d", height, x, len(values[x]))
+		}
+	}
+	return nil
+}
+
+// toColorful converts a color to a colorful.Color without losing precision if
+// it already is one
+func toColorful(col color.Color) colorful.Color {
+	if converted, ok := col.(colorful.Color); ok {
+		return converted
+	}
+	converted, _ := colorful.MakeColor(col)
+	return converted
+}
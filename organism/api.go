@@ -1,6 +1,7 @@
 package organism
 
 import (
+	"github.com/Zebbeni/protozoa/config"
 	"github.com/Zebbeni/protozoa/food"
 	"github.com/Zebbeni/protozoa/utils"
 )
@@ -17,9 +18,19 @@ type LookupAPI interface {
 	CheckOrganismAtPoint(point utils.Point, checkFunc OrgCheck) bool
 	GetFoodAtPoint(point utils.Point) (*food.Item, bool)
 	GetPhAtPoint(point utils.Point) float64
+	// GetFieldAtPoint returns the value at a point of the field with the
+	// given index in the config's fields
+	GetFieldAtPoint(field int, point utils.Point) float64
+	// GetLightAtPoint returns the light shining on a point this cycle, before
+	// any shade from organisms
+	GetLightAtPoint(point utils.Point) float64
 	OrganismCount() int
 	Cycle() int
 	GetSelected() int
+	// Config returns the parameters of the simulation the organism lives in
+	Config() *config.Globals
+	// Geometry returns the dimensions and walls of the simulation grid
+	Geometry() *utils.Geometry
 }
 
 // ChangeAPI provides callback functions to make changes to the simulation
@@ -3,8 +3,6 @@ package organism
 import (
 	"image/color"
 	"math"
-	"math/rand"
-	"sync"
 
 	c "github.com/Zebbeni/protozoa/config"
 	d "github.com/Zebbeni/protozoa/decision"
@@ -30,16 +28,19 @@ type Organism struct {
 	action       d.Action
 
 	lookupAPI LookupAPI
-
-	mutex sync.Mutex
-}
-
-// NewRandom initializes organism at with random grid location and direction
-func NewRandom(id int, point utils.Point, api LookupAPI) *Organism {
-	traits := newRandomTraits()
-	decisionTree := d.TreeFromAction(d.GetRandomAction())
-	for mutations := 0; mutations < c.InitialDecisionTreeMutations(); mutations++ {
-		decisionTree = d.MutateTree(decisionTree)
+	cfg       *c.Globals
+	geometry  *utils.Geometry
+	random    *utils.Random
+}
+
+// NewRandom initializes organism at with random grid location and direction.
+// The organism draws all of its random values from the given stream.
+func NewRandom(id int, point utils.Point, api LookupAPI, random *utils.Random) *Organism {
+	cfg := api.Config()
+	traits := newRandomTraits(cfg, random.Rand)
+	decisionTree := d.TreeFromAction(d.GetRandomAction(random.Rand, cfg.EightDirections))
+	for mutations := 0; mutations < cfg.InitialDecisionTreeMutations; mutations++ {
+		decisionTree = d.MutateTree(decisionTree, cfg.MaxDecisionTreeSize, cfg.EightDirections, random.Rand)
 	}
 	organism := Organism{
 		ID:                   id,
@@ -49,7 +50,7 @@ func NewRandom(id int, point utils.Point, api LookupAPI) *Organism {
 		Children:             0,
 		CyclesSinceLastSpawn: 0,
 		Location:             point,
-		Direction:            utils.GetRandomDirection(),
+		Direction:            api.Geometry().RandomDirection(random.Rand),
 		OriginalAncestorID:   id,
 
 		traits:       traits,
@@ -57,16 +58,20 @@ func NewRandom(id int, point utils.Point, api LookupAPI) *Organism {
 		action:       d.ActChemosynthesis,
 
 		lookupAPI: api,
+		cfg:       cfg,
+		geometry:  api.Geometry(),
+		random:    random,
 	}
 	return &organism
 }
 
-// NewChild initializes and returns a new organism with a copied TreeLibrary from its parent
+// NewChild initializes and returns a new organism with a copied TreeLibrary from its parent.
+// All mutations are drawn from the parent's random stream, which also seeds the child's.
 func (o *Organism) NewChild(id int, point utils.Point, api LookupAPI) *Organism {
-	traits := o.traits.copyMutated()
+	traits := o.traits.copyMutated(o.cfg, o.random.Rand)
 	inheritedTree := o.GetDecisionTreeCopy()
-	if rand.Float64() < o.ChanceToMutateDecisionTree() {
-		inheritedTree = d.MutateTree(inheritedTree)
+	if o.random.Float64() < o.ChanceToMutateDecisionTree() {
+		inheritedTree = d.MutateTree(inheritedTree, o.cfg.MaxDecisionTreeSize, o.cfg.EightDirections, o.random.Rand)
 	}
 	organism := Organism{
 		ID:                   id,
@@ -76,7 +81,7 @@ func (o *Organism) NewChild(id int, point utils.Point, api LookupAPI) *Organism
 		Children:             0,
 		CyclesSinceLastSpawn: 0,
 		Location:             point,
-		Direction:            utils.GetRandomDirection(),
+		Direction:            o.geometry.RandomDirection(o.random.Rand),
 		OriginalAncestorID:   o.OriginalAncestorID,
 
 		traits:       traits,
@@ -84,6 +89,9 @@ func (o *Organism) NewChild(id int, point utils.Point, api LookupAPI) *Organism
 		action:       d.ActChemosynthesis,
 
 		lookupAPI: api,
+		cfg:       o.cfg,
+		geometry:  o.geometry,
+		random:    o.random.NewStream(),
 	}
 	return &organism
 }
@@ -130,7 +138,7 @@ func (o *Organism) shouldSpawn() bool {
 	if o.Health < o.MinHealthToSpawn() {
 		return false
 	}
-	if o.lookupAPI.OrganismCount() >= c.MaxOrganisms() {
+	if o.lookupAPI.OrganismCount() >= o.cfg.MaxOrganisms {
 		return false
 	}
 	return true
@@ -184,6 +192,18 @@ func (o *Organism) isConditionTrue(cond interface{}) bool {
 		return o.isHealthyPhHere()
 	case d.IsHealthierPhAhead:
 		return o.isHealthierPhAhead()
+	case d.IsFoodAheadLeft:
+		return o.isFoodAheadLeft()
+	case d.IsFoodAheadRight:
+		return o.isFoodAheadRight()
+	case d.IsOrganismAheadLeft:
+		return o.isOrganismAheadLeft()
+	case d.IsRelatedOrganismAheadLeft:
+		return o.isRelatedOrganismAheadLeft()
+	case d.IsOrganismAheadRight:
+		return o.isOrganismAheadRight()
+	case d.IsRelatedOrganismAheadRight:
+		return o.isRelatedOrganismAheadRight()
 	}
 	return false
 }
@@ -249,21 +269,29 @@ func (o *Organism) ApplyHealthChange(change float64) {
 	if o.Health > o.Size {
 		// When health increase causes size to increase, increase slowly, not all at once.
 		difference := o.Health - o.Size
-		o.Size = math.Min(o.Size+(difference*c.GrowthFactor()), o.traits.MaxSize)
+		o.Size = math.Min(o.Size+(difference*o.cfg.GrowthFactor), o.traits.MaxSize)
 	}
 	o.Health = math.Min(o.Health, o.Size)
 }
 
 func (o *Organism) isFoodAhead() bool {
-	return o.isFoodAtPoint(o.Location.Add(o.Direction))
+	return o.isFoodAtPoint(o.geometry.Add(o.Location, o.Direction))
 }
 
 func (o *Organism) isFoodLeft() bool {
-	return o.isFoodAtPoint(o.Location.Add(o.Direction.Left()))
+	return o.isFoodAtPoint(o.geometry.Add(o.Location, o.Direction.Left()))
 }
 
 func (o *Organism) isFoodRight() bool {
-	return o.isFoodAtPoint(o.Location.Add(o.Direction.Right()))
+	return o.isFoodAtPoint(o.geometry.Add(o.Location, o.Direction.Right()))
+}
+
+func (o *Organism) isFoodAheadLeft() bool {
+	return o.isFoodAtPoint(o.geometry.Add(o.Location, o.Direction.HalfLeft()))
+}
+
+func (o *Organism) isFoodAheadRight() bool {
+	return o.isFoodAtPoint(o.geometry.Add(o.Location, o.Direction.HalfRight()))
 }
 
 func (o *Organism) isFoodAtPoint(point utils.Point) bool {
@@ -273,35 +301,51 @@ func (o *Organism) isFoodAtPoint(point utils.Point) bool {
 }
 
 func (o *Organism) isOrganismAhead() bool {
-	return o.isOrganismAtPoint(o.Location.Add(o.Direction))
+	return o.isOrganismAtPoint(o.geometry.Add(o.Location, o.Direction))
 }
 
 func (o *Organism) isWallAhead() bool {
-	return o.isWallAtPoint(o.Location.Add(o.Direction))
+	return o.isWallAtPoint(o.geometry.Add(o.Location, o.Direction))
 }
 
 func (o *Organism) isBiggerOrganismAhead() bool {
-	return o.isBiggerOrganismAtPoint(o.Location.Add(o.Direction))
+	return o.isBiggerOrganismAtPoint(o.geometry.Add(o.Location, o.Direction))
 }
 
 func (o *Organism) isRelatedOrganismAhead() bool {
-	return o.isRelatedOrganismAtPoint(o.Location.Add(o.Direction))
+	return o.isRelatedOrganismAtPoint(o.geometry.Add(o.Location, o.Direction))
 }
 
 func (o *Organism) isOrganismLeft() bool {
-	return o.isOrganismAtPoint(o.Location.Add(o.Direction.Left()))
+	return o.isOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.Left()))
 }
 
 func (o *Organism) isRelatedOrganismLeft() bool {
-	return o.isRelatedOrganismAtPoint(o.Location.Add(o.Direction.Left()))
+	return o.isRelatedOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.Left()))
 }
 
 func (o *Organism) isOrganismRight() bool {
-	return o.isOrganismAtPoint(o.Location.Add(o.Direction.Right()))
+	return o.isOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.Right()))
 }
 
 func (o *Organism) isRelatedOrganismRight() bool {
-	return o.isRelatedOrganismAtPoint(o.Location.Add(o.Direction.Right()))
+	return o.isRelatedOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.Right()))
+}
+
+func (o *Organism) isOrganismAheadLeft() bool {
+	return o.isOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.HalfLeft()))
+}
+
+func (o *Organism) isRelatedOrganismAheadLeft() bool {
+	return o.isRelatedOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.HalfLeft()))
+}
+
+func (o *Organism) isOrganismAheadRight() bool {
+	return o.isOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.HalfRight()))
+}
+
+func (o *Organism) isRelatedOrganismAheadRight() bool {
+	return o.isRelatedOrganismAtPoint(o.geometry.Add(o.Location, o.Direction.HalfRight()))
 }
 
 func (o *Organism) isHealthyPhHere() bool {
@@ -309,7 +353,7 @@ func (o *Organism) isHealthyPhHere() bool {
 }
 
 func (o *Organism) isHealthierPhAhead() bool {
-	return o.isPhHealthierAtPoint(o.Location, o.Location.Add(o.Direction), o.Traits().IdealPh)
+	return o.isPhHealthierAtPoint(o.Location, o.geometry.Add(o.Location, o.Direction), o.Traits().IdealPh)
 }
 
 func (o *Organism) isBiggerOrganismAtPoint(p utils.Point) bool {
@@ -331,7 +375,7 @@ func (o *Organism) isOrganismAtPoint(p utils.Point) bool {
 }
 
 func (o *Organism) isWallAtPoint(p utils.Point) bool {
-	return p.IsWall()
+	return o.geometry.IsWall(p)
 }
 
 func (o *Organism) checkOrganismAtPoint(p utils.Point, checkFunc OrgCheck) bool {
@@ -344,13 +388,16 @@ func (o *Organism) isPhHealthyAtPoint(p utils.Point, ideal, tolerance float64) b
 }
 
 func (o *Organism) isPhHealthierAtPoint(control, test utils.Point, ideal float64) bool {
+	if !o.geometry.InBounds(test) {
+		return false
+	}
 	controlPh := o.lookupAPI.GetPhAtPoint(control)
 	testPh := o.lookupAPI.GetPhAtPoint(test)
 	return math.Abs(testPh-ideal) < math.Abs(controlPh-ideal)
 }
 
 func (o *Organism) canMove() bool {
-	if o.isWallAhead() {
+	if o.isWallAhead() || o.geometry.CutsCorner(o.Location, o.Direction) {
 		return false
 	}
 	if o.isOrganismAhead() {
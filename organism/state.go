@@ -0,0 +1,125 @@
+package organism
+
+import (
+	"fmt"
+
+	d "github.com/Zebbeni/protozoa/decision"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// State contains everything needed to save an Organism and later restore it
+// exactly as it was
+type State struct {
+	ID                   int
+	Age                  int
+	Health               float64
+	Size                 float64
+	Children             int
+	TraveledDist         int
+	CyclesSinceLastSpawn int
+	Location             utils.Point
+	Direction            utils.Point
+	OriginalAncestorID   int
+	Traits               Traits
+	DecisionTree         string
+	Action               d.Action
+	RandomState          uint64
+}
+
+// State returns the full saveable state of an Organism
+func (o *Organism) State() State {
+	return State{
+		ID:                   o.ID,
+		Age:                  o.Age,
+		Health:               o.Health,
+		Size:                 o.Size,
+		Children:             o.Children,
+		TraveledDist:         o.TraveledDist,
+		CyclesSinceLastSpawn: o.CyclesSinceLastSpawn,
+		Location:             o.Location,
+		Direction:            o.Direction,
+		OriginalAncestorID:   o.OriginalAncestorID,
+		Traits:               o.traits,
+		DecisionTree:         o.decisionTree.Serialize(),
+		Action:               o.action,
+		RandomState:          o.random.State(),
+	}
+}
+
+// FromState restores an Organism from a previously-saved State
+func FromState(state State, api LookupAPI) (*Organism, error) {
+	decisionTree, err := d.TreeFromString(state.DecisionTree)
+	if err != nil {
+		return nil, err
+	}
+	if fields := len(api.Config().Fields); len(state.Traits.FieldTraits) != fields {
+		return nil, fmt.Errorf("expected traits for %d fields, found %d", fields, len(state.Traits.FieldTraits))
+	}
+	organism := Organism{
+		ID:                   state.ID,
+		Age:                  state.Age,
+		Health:               state.Health,
+		Size:                 state.Size,
+		Children:             state.Children,
+		TraveledDist:         state.TraveledDist,
+		CyclesSinceLastSpawn: state.CyclesSinceLastSpawn,
+		Location:             state.Location,
+		Direction:            state.Direction,
+		OriginalAncestorID:   state.OriginalAncestorID,
+
+		traits:       state.Traits,
+		decisionTree: decisionTree,
+		action:       state.Action,
+
+		lookupAPI: api,
+		cfg:       api.Config(),
+		geometry:  api.Geometry(),
+		random:    utils.RandomFromState(state.RandomState),
+	}
+	return &organism, nil
+}
+
+// Changes contains the parts of an Organism's state that may change from one
+// cycle to the next, used to replay a recorded simulation
+type Changes struct {
+	ID                   int
+	Age                  int
+	Health               float64
+	Size                 float64
+	Children             int
+	TraveledDist         int
+	CyclesSinceLastSpawn int
+	Location             utils.Point
+	Direction            utils.Point
+	Action               d.Action
+}
+
+// Changes returns the parts of an Organism's state that may change from one
+// cycle to the next
+func (o *Organism) Changes() Changes {
+	return Changes{
+		ID:                   o.ID,
+		Age:                  o.Age,
+		Health:               o.Health,
+		Size:                 o.Size,
+		Children:             o.Children,
+		TraveledDist:         o.TraveledDist,
+		CyclesSinceLastSpawn: o.CyclesSinceLastSpawn,
+		Location:             o.Location,
+		Direction:            o.Direction,
+		Action:               o.action,
+	}
+}
+
+// ApplyChanges sets an Organism's state to match previously-recorded Changes
+func (o *Organism) ApplyChanges(changes Changes) {
+	o.Age = changes.Age
+	o.Health = changes.Health
+	o.Size = changes.Size
+	o.Children = changes.Children
+	o.TraveledDist = changes.TraveledDist
+	o.CyclesSinceLastSpawn = changes.CyclesSinceLastSpawn
+	o.Location = changes.Location
+	o.Direction = changes.Direction
+	o.action = changes.Action
+}
@@ -43,18 +43,32 @@ type Traits struct {
 	// current location, a small positive or negative number which gets
 	// multiplied by the organism's current size
 	PhGrowthEffect float64
+	// FieldTraits: the organism's ideal value and tolerance for each of the
+	// environment's other fields, in the order they are configured
+	FieldTraits []FieldTrait
 }
 
-func newRandomTraits() Traits {
-	organismColor := getRandomColor()
-	maxSize := rand.Float64() * c.MaximumMaxSize()
-	spawnHealth := rand.Float64() * maxSize * c.MaxSpawnHealthPercent()
-	minHealthToSpawn := spawnHealth + rand.Float64()*(maxSize-spawnHealth)
-	minCyclesBetweenSpawns := rand.Intn(c.MaxCyclesBetweenSpawns())
-	chanceToMutateDecisionTree := math.Max(c.MinChanceToMutateDecisionTree(), rand.Float64()*c.MaxChanceToMutateDecisionTree())
-	idealPh := (c.MaxIdealPh() + c.MinIdealPh()) / 2.0
-	phTolerance := rand.Float64() * c.MaxPhTolerance()
-	phGrowthEffect := rand.Float64()*(c.MaxOrganismPhGrowthEffect()*2.0) - c.MaxOrganismPhGrowthEffect()
+// FieldTrait is the range of an environment field's values an organism can
+// tolerate without suffering health damage
+type FieldTrait struct {
+	Ideal     float64
+	Tolerance float64
+}
+
+// fieldMutationFraction is the largest change to a field trait when mutated,
+// as a fraction of the field's full range
+const fieldMutationFraction = 0.01
+
+func newRandomTraits(cfg *c.Globals, r *rand.Rand) Traits {
+	organismColor := getRandomColor(r)
+	maxSize := r.Float64() * cfg.MaximumMaxSize
+	spawnHealth := r.Float64() * maxSize * cfg.MaxSpawnHealthPercent
+	minHealthToSpawn := spawnHealth + r.Float64()*(maxSize-spawnHealth)
+	minCyclesBetweenSpawns := r.Intn(cfg.MaxCyclesBetweenSpawns)
+	chanceToMutateDecisionTree := math.Max(cfg.MinChanceToMutateDecisionTree, r.Float64()*cfg.MaxChanceToMutateDecisionTree)
+	idealPh := (cfg.MaxIdealPh + cfg.MinIdealPh) / 2.0
+	phTolerance := r.Float64() * cfg.MaxPhTolerance
+	phGrowthEffect := r.Float64()*(cfg.MaxOrganismPhGrowthEffect*2.0) - cfg.MaxOrganismPhGrowthEffect
 	return Traits{
 		OrganismColor:              organismColor,
 		MaxSize:                    maxSize,
@@ -65,27 +79,39 @@ func newRandomTraits() Traits {
 		IdealPh:                    idealPh,
 		PhTolerance:                phTolerance,
 		PhGrowthEffect:             phGrowthEffect,
+		FieldTraits:                newRandomFieldTraits(cfg, r),
 	}
 }
 
-func (t Traits) copyMutated() Traits {
-	organismColor := mutateColor(t.OrganismColor)
+func newRandomFieldTraits(cfg *c.Globals, r *rand.Rand) []FieldTrait {
+	traits := make([]FieldTrait, len(cfg.Fields))
+	for i, field := range cfg.Fields {
+		traits[i] = FieldTrait{
+			Ideal:     (field.MaxIdeal + field.MinIdeal) / 2.0,
+			Tolerance: r.Float64() * field.MaxTolerance,
+		}
+	}
+	return traits
+}
+
+func (t Traits) copyMutated(cfg *c.Globals, r *rand.Rand) Traits {
+	organismColor := mutateColor(r, t.OrganismColor)
 	// maxSize = previous +- previous +- <5.0, bounded by MinimumMaxSize and MaximumMaxSize
-	maxSize := mutateFloat(t.MaxSize, 5.0, c.MinimumMaxSize(), c.MaximumMaxSize())
+	maxSize := mutateFloat(r, t.MaxSize, 5.0, cfg.MinimumMaxSize, cfg.MaximumMaxSize)
 	// minCyclesBetweenSpawns = previous +- <=5, bounded by 0 and MaxCyclesBetweenSpawns
-	minCyclesBetweenSpawns := mutateInt(t.MinCyclesBetweenSpawns, 5, 0, c.MaxCyclesBetweenSpawns())
+	minCyclesBetweenSpawns := mutateInt(r, t.MinCyclesBetweenSpawns, 5, 0, cfg.MaxCyclesBetweenSpawns)
 	// spawnHealth = previous +- <0.5, bounded by MinSpawnHealth and maxSize
-	spawnHealth := mutateFloat(t.SpawnHealth, 0.5, c.MinSpawnHealth(), maxSize*c.MaxSpawnHealthPercent())
+	spawnHealth := mutateFloat(r, t.SpawnHealth, 0.5, cfg.MinSpawnHealth, maxSize*cfg.MaxSpawnHealthPercent)
 	// minHealthToSpawn = previous +- <5.0, bounded by spawnHealthPercent and maxSize (both calculated above)
-	minHealthToSpawn := mutateFloat(t.MinHealthToSpawn, 5.0, spawnHealth, maxSize)
+	minHealthToSpawn := mutateFloat(r, t.MinHealthToSpawn, 5.0, spawnHealth, maxSize)
 	// chanceToMutateDecisionTree = previous +- <0.05, bounded by MinChanceToMutateDecisionTree and MaxChanceToMutateDecisionTree
-	chanceToMutateDecisionTree := mutateFloat(t.ChanceToMutateDecisionTree, 0.05, c.MinChanceToMutateDecisionTree(), c.MaxChanceToMutateDecisionTree())
+	chanceToMutateDecisionTree := mutateFloat(r, t.ChanceToMutateDecisionTree, 0.05, cfg.MinChanceToMutateDecisionTree, cfg.MaxChanceToMutateDecisionTree)
 	// phEffect = previous +- 0.001, bounded by MaxOrganismPhGrowthEffect (and -1 * MaxOrganismPhGrowthEffect)
-	phEffect := mutateFloat(t.PhGrowthEffect, .001, c.MaxOrganismPhGrowthEffect()*-1, c.MaxOrganismPhGrowthEffect())
+	phEffect := mutateFloat(r, t.PhGrowthEffect, .001, cfg.MaxOrganismPhGrowthEffect*-1, cfg.MaxOrganismPhGrowthEffect)
 	// ideaLPh = previous += 0.1, bounded by MinIdealPh and MaxIdealPh
-	idealPh := mutateFloat(t.IdealPh, 0.1, c.MinIdealPh(), c.MaxIdealPh())
+	idealPh := mutateFloat(r, t.IdealPh, 0.1, cfg.MinIdealPh, cfg.MaxIdealPh)
 	// phTolerance = previous +- 0.1, bounded by MinPhTolerance and MaxPhTolerance
-	phTolerance := mutateFloat(t.PhTolerance, 0.1, c.MinPhTolerance(), c.MaxPhTolerance())
+	phTolerance := mutateFloat(r, t.PhTolerance, 0.1, cfg.MinPhTolerance, cfg.MaxPhTolerance)
 	return Traits{
 		OrganismColor:              organismColor,
 		MaxSize:                    maxSize,
@@ -96,45 +122,58 @@ func (t Traits) copyMutated() Traits {
 		IdealPh:                    idealPh,
 		PhTolerance:                phTolerance,
 		PhGrowthEffect:             phEffect,
+		FieldTraits:                t.copyMutatedFieldTraits(cfg, r),
+	}
+}
+
+func (t Traits) copyMutatedFieldTraits(cfg *c.Globals, r *rand.Rand) []FieldTrait {
+	traits := make([]FieldTrait, len(cfg.Fields))
+	for i, field := range cfg.Fields {
+		maxChange := fieldMutationFraction * (field.Max - field.Min)
+		traits[i] = FieldTrait{
+			Ideal:     mutateFloat(r, t.FieldTraits[i].Ideal, maxChange, field.MinIdeal, field.MaxIdeal),
+			Tolerance: mutateFloat(r, t.FieldTraits[i].Tolerance, maxChange, field.MinTolerance, field.MaxTolerance),
+		}
 	}
+	return traits
 }
 
-func mutateFloat(value, maxChange, min, max float64) float64 {
-	mutated := value + maxChange - rand.Float64()*maxChange*2.0
+func mutateFloat(r *rand.Rand, value, maxChange, min, max float64) float64 {
+	mutated := value + maxChange - r.Float64()*maxChange*2.0
 	return math.Min(math.Max(mutated, min), max)
 }
 
-func mutateInt(value, maxChange, min, max int) int {
-	mutated := math.Round(float64(value) + rand.Float64()*float64(maxChange)*2.0 - (float64(maxChange)))
+func mutateInt(r *rand.Rand, value, maxChange, min, max int) int {
+	mutated := math.Round(float64(value) + r.Float64()*float64(maxChange)*2.0 - (float64(maxChange)))
 	return int(math.Min(math.Max(mutated, float64(min)), float64(max)))
 }
 
 // MutateColor returns a slight variation on a given color
-func mutateColor(originalColor colorful.Color) colorful.Color {
+func mutateColor(r *rand.Rand, originalColor colorful.Color) colorful.Color {
 	h, s, l := originalColor.HSLuv()
-	h = mutateHue(h)
-	s = mutateSaturation(s)
-	l = mutateLuminance(l)
+	h = mutateHue(r, h)
+	s = mutateSaturation(r, s)
+	l = mutateLuminance(r, l)
 	return colorful.HSLuv(h, s, l)
 }
 
-func mutateHue(h float64) float64 {
-	return math.Mod(h+360.0+(rand.Float64()*maxHueMutation*2.0)-maxHueMutation, 360)
+func mutateHue(r *rand.Rand, h float64) float64 {
+	return math.Mod(h+360.0+(r.Float64()*maxHueMutation*2.0)-maxHueMutation, 360)
 }
 
-func mutateSaturation(s float64) float64 {
-	s += rand.Float64()*maxSaturationMutation*2.0 - maxSaturationMutation
+func mutateSaturation(r *rand.Rand, s float64) float64 {
+	s += r.Float64()*maxSaturationMutation*2.0 - maxSaturationMutation
 	return math.Min(math.Max(s, minSaturation), maxSaturation)
 }
 
-func mutateLuminance(l float64) float64 {
-	l += rand.Float64()*maxLuminanceMutation*2.0 - maxLuminanceMutation
+func mutateLuminance(r *rand.Rand, l float64) float64 {
+	l += r.Float64()*maxLuminanceMutation*2.0 - maxLuminanceMutation
 	return math.Min(math.Max(l, minLuminance), maxLuminance)
 }
 
-func getRandomColor() colorful.Color {
-	h := rand.Float64() * 360.0
-	s := minSaturation + (rand.Float64() * (maxSaturation - minSaturation))
-	l := minLuminance + (rand.Float64() * (maxLuminance - minLuminance))
+func getRandomColor(r *rand.Rand) colorful.Color {
+	h := r.Float64() * 360.0
+	s := minSaturation + (r.Float64() * (maxSaturation - minSaturation))
+	l := minLuminance + (r.Float64() * (maxLuminance - minLuminance))
 	return colorful.HSLuv(h, s, l)
 }
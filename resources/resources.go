@@ -8,8 +8,6 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/Zebbeni/protozoa/config"
-
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -46,10 +44,11 @@ var (
 	SquareBox *ebiten.Image
 )
 
-// Init loads all fonts and images to be used in the UI
-func Init() {
+// Init loads all fonts and images to be used in the UI, with grid images sized
+// for the given grid unit size
+func Init(gridUnitSize int) {
 	initFonts()
-	initImages()
+	initImages(gridUnitSize)
 }
 
 func initFonts() {
@@ -61,13 +60,13 @@ func initFonts() {
 	FontSourceCodePro8 = fontFace(sourceCode, 8)
 }
 
-func initImages() {
+func initImages(gridUnitSize int) {
 	// Panel Images
 	PlayButton = loadImage("resources/images/play_button.png")
 	PauseButton = loadImage("resources/images/pause_button.png")
 
 	var dir string
-	switch config.GridUnitSize() {
+	switch gridUnitSize {
 	case 4:
 		dir = "4x4"
 		break
@@ -78,7 +77,7 @@ func initImages() {
 		dir = "8x8"
 		break
 	default:
-		panic(fmt.Sprintf("Unsupported grid unit size: %d", config.GridUnitSize()))
+		panic(fmt.Sprintf("Unsupported grid unit size: %d", gridUnitSize))
 	}
 	SquareSmall = loadImage(fmt.Sprintf("resources/images/grid/%s/square_small.png", dir))
 	SquareMedium = loadImage(fmt.Sprintf("resources/images/grid/%s/square_large.png", dir))
@@ -0,0 +1,147 @@
+//go:build !headless
+
+package runner
+
+import (
+	"log"
+	"time"
+
+	c "github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/resources"
+	"github.com/Zebbeni/protozoa/simulation"
+	"github.com/Zebbeni/protozoa/ux"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fastestFrameTime is the time spent running cycles between frames when
+// running as fast as possible
+const fastestFrameTime = 100 * time.Millisecond
+
+type Runner struct {
+	sim      *simulation.Simulation
+	ui       *ux.Interface
+	recorder *simulation.Recorder
+	metrics  *simulation.MetricsWriter
+
+	// changesFile is where setting changes are saved, and changesSaved the
+	// number saved so far
+	changesFile  string
+	changesSaved int
+
+	pressedKeys map[ebiten.Key]bool
+}
+
+func (r *Runner) Update() error {
+	if err := r.handleUserInput(); err != nil {
+		return err
+	}
+	if r.ui.IsReplay() {
+		if err := r.ui.UpdateReplay(); err != nil {
+			return err
+		}
+	} else if r.sim.IsPaused() {
+		r.step(r.ui.TakeRequestedSteps())
+	} else {
+		r.runCycles()
+	}
+	r.updateSelected()
+	return nil
+}
+
+// runCycles runs as many cycles as the chosen speed allows between frames.
+// Points updated by every cycle accumulate until the next Draw clears them, so
+// the grid still redraws everything that changed.
+func (r *Runner) runCycles() {
+	if cycles := r.ui.CyclesPerFrame(); cycles > 0 {
+		r.step(cycles)
+		return
+	}
+	// run as fast as possible, only stopping often enough to draw the
+	// occasional frame
+	start := time.Now()
+	for time.Since(start) < fastestFrameTime {
+		r.step(1)
+	}
+}
+
+// step runs the next n cycles of the simulation, recording and saving
+// snapshots of each as needed
+func (r *Runner) step(n int) {
+	for i := 0; i < n; i++ {
+		r.sim.Step(1)
+		recordCycle(r.recorder)
+		writeMetrics(r.metrics)
+		saveSnapshotIfDue(r.sim)
+		r.changesSaved = saveSettingChanges(r.sim, r.changesFile, r.changesSaved)
+	}
+}
+
+func (r *Runner) handleUserInput() error {
+	return r.ui.HandleUserInput()
+}
+
+func (r *Runner) updateSelected() {
+	r.ui.UpdateSelected()
+}
+
+func (r *Runner) Draw(screen *ebiten.Image) {
+	r.ui.Render(screen)
+	r.sim.ClearUpdatedPoints()
+}
+
+func (r *Runner) Layout(_, _ int) (int, int) {
+	return r.sim.Config().WindowSize()
+}
+
+// runGUI runs a simulation, or plays back a recording, in a window until the
+// window is closed
+func runGUI(opts *c.Options, globals *c.Globals) {
+	if opts.ReplayFile != "" {
+		runReplay(opts)
+		return
+	}
+
+	sim := newSimulation(opts, globals)
+	resources.Init(sim.Config().UnitSize())
+
+	recorder := newRecorder(sim, opts)
+	defer closeRecorder(recorder)
+	metrics := newMetricsWriter(sim, opts)
+	defer closeMetricsWriter(metrics)
+
+	run(&Runner{
+		sim:         sim,
+		ui:          ux.NewInterface(sim),
+		recorder:    recorder,
+		metrics:     metrics,
+		changesFile: opts.ChangesOutFile,
+		pressedKeys: map[ebiten.Key]bool{},
+	})
+}
+
+// runReplay plays back a recording in a window until the window is closed
+func runReplay(opts *c.Options) {
+	replay, err := simulation.OpenReplay(opts, opts.ReplayFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer replay.Close()
+
+	sim := replay.Simulation()
+	resources.Init(sim.Config().UnitSize())
+
+	run(&Runner{
+		sim:         sim,
+		ui:          ux.NewReplayInterface(replay),
+		pressedKeys: map[ebiten.Key]bool{},
+	})
+}
+
+func run(gameRunner *Runner) {
+	ebiten.SetWindowSize(gameRunner.sim.Config().WindowSize())
+	ebiten.SetWindowResizable(true)
+	ebiten.SetScreenClearedEveryFrame(false)
+	if err := ebiten.RunGame(gameRunner); err != nil {
+		log.Print(err)
+	}
+}
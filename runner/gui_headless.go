@@ -0,0 +1,19 @@
+//go:build headless
+
+package runner
+
+import (
+	"log"
+
+	c "github.com/Zebbeni/protozoa/config"
+)
+
+// runGUI runs the simulation headless instead, since binaries built with the
+// headless tag include no visualization
+func runGUI(opts *c.Options, globals *c.Globals) {
+	if opts.ReplayFile != "" {
+		log.Fatal("recordings can only be replayed with visualization")
+	}
+	log.Println("built without visualization, running headless")
+	runHeadless(opts, globals)
+}
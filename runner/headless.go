@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	c "github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/simulation"
+)
+
+// trial describes a single headless run
+type trial struct {
+	index   int
+	seed    int
+	globals *c.Globals
+}
+
+// trialResult contains the outcome of a single headless trial
+type trialResult struct {
+	trial     int
+	seed      int
+	cycles    int
+	runtime   time.Duration
+	reason    string
+	organisms int
+	dead      int
+	averagePh float64
+}
+
+// runHeadless runs all trials without visualization and prints a summary of
+// their results
+func runHeadless(opts *c.Options, globals *c.Globals) {
+	trials := make([]trial, opts.TrialCount)
+	for i := range trials {
+		// give each trial its own seed so trials don't repeat each other
+		trials[i] = trial{index: i, seed: opts.Seed + i, globals: globals}
+	}
+	start := time.Now()
+	results := runTrials(opts, trials)
+	printSummary(results, time.Since(start))
+}
+
+// runTrials runs the given trials, up to opts.Parallel at once, and returns
+// their results in the same order
+func runTrials(opts *c.Options, trials []trial) []trialResult {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > len(trials) {
+		parallel = len(trials)
+	}
+
+	results := make([]trialResult, len(trials))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = runTrial(opts, trials[i], parallel > 1, len(trials) > 1)
+			}
+		}()
+	}
+	for i := range trials {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// runTrial runs a single headless trial to completion. Each trial uses its own
+// copy of the options so trials share no state and can safely run
+// concurrently. Numbered trials write to files numbered by trial.
+func runTrial(opts *c.Options, t trial, isParallel, isNumbered bool) trialResult {
+	trialOpts := *opts
+	trialOpts.Seed = t.seed
+	if isNumbered {
+		// keep concurrent trials from overwriting each other's files
+		trialOpts.SnapshotFile = trialPath(opts.SnapshotFile, t.index)
+		trialOpts.RecordFile = trialPath(opts.RecordFile, t.index)
+		trialOpts.MetricsFile = trialPath(opts.MetricsFile, t.index)
+	}
+
+	sim := newSimulation(&trialOpts, t.globals)
+	recorder := newRecorder(sim, &trialOpts)
+	defer closeRecorder(recorder)
+	metrics := newMetricsWriter(sim, &trialOpts)
+	defer closeMetricsWriter(metrics)
+	start := time.Now()
+	for !sim.IsDone() {
+		sim.Update()
+		recordCycle(recorder)
+		writeMetrics(metrics)
+		sim.ClearUpdatedPoints()
+		saveSnapshotIfDue(sim)
+		if sim.Cycle()%100 == 0 {
+			printProgress(sim, t.index, isParallel)
+		}
+	}
+	result := trialResult{
+		trial:     t.index,
+		seed:      t.seed,
+		cycles:    sim.Cycle() + 1,
+		runtime:   time.Since(start),
+		reason:    sim.EndReason(),
+		organisms: sim.OrganismCount(),
+		dead:      sim.GetDeadCount(),
+		averagePh: sim.AveragePh(),
+	}
+	fmt.Printf("\nSimulation %d ended after %d cycles (%s), total runtime: %s\n", t.index, result.cycles, result.reason, result.runtime)
+	return result
+}
+
+// trialPath returns a file path numbered for a single trial, so that
+// "run.csv" becomes "run.3.csv" for trial 3
+func trialPath(path string, trial int) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), trial, ext)
+}
+
+// printProgress prints the current status of a trial. Lines from trials run in
+// parallel are printed whole and labeled so they can be told apart.
+func printProgress(sim *simulation.Simulation, trial int, isParallel bool) {
+	regimes := ""
+	if active := sim.ActiveRegimes(); len(active) > 0 {
+		regimes = "   Regime: " + strings.Join(active, ", ")
+	}
+	if isParallel {
+		fmt.Printf("Trial: %3d   Cycle: %6d   Organisms: %d   AvgPh: %2.2f%s\n", trial, sim.Cycle(), sim.OrganismCount(), sim.AveragePh(), regimes)
+		return
+	}
+	fmt.Printf("\nCycle: %6d   Organisms: %d   AvgPh: %2.2f%s", sim.Cycle(), sim.OrganismCount(), sim.AveragePh(), regimes)
+}
+
+// printSummary prints the results of every trial in order, including the end
+// condition that stopped it, followed by the average number of cycles the
+// trials ran for
+func printSummary(results []trialResult, elapsed time.Duration) {
+	if len(results) == 0 {
+		return
+	}
+	var sb strings.Builder
+	sumAllCycles := 0
+	sb.WriteString("\nTrial     Seed     Cycles   Runtime        Ended By\n")
+	for _, r := range results {
+		sumAllCycles += r.cycles
+		sb.WriteString(fmt.Sprintf("%5d %8d %10d   %-14s %s\n", r.trial, r.seed, r.cycles, r.runtime.Round(time.Millisecond), r.reason))
+	}
+	avgCycles := sumAllCycles / len(results)
+	sb.WriteString(fmt.Sprintf("\nTotal runtime for all trials: %s\n", elapsed))
+	sb.WriteString(fmt.Sprintf("Average number of cycles: %d\n", avgCycles))
+	fmt.Print(sb.String())
+}
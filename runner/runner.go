@@ -3,80 +3,147 @@ package runner
 import (
 	"fmt"
 	"log"
-	"time"
 
 	c "github.com/Zebbeni/protozoa/config"
-	"github.com/Zebbeni/protozoa/resources"
 	"github.com/Zebbeni/protozoa/simulation"
-	"github.com/Zebbeni/protozoa/ux"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-type Runner struct {
-	sim *simulation.Simulation
-	ui  *ux.Interface
-
-	pressedKeys map[ebiten.Key]bool
+// RunSimulation runs a simulation with the given options and config, either
+// headless or in a window
+func RunSimulation(opts *c.Options, globals *c.Globals) {
+	if opts.ResumeFile != "" && opts.TrialCount > 1 {
+		// every trial would restore the same state and random streams
+		log.Fatal("a snapshot can only be resumed as a single trial")
+	}
+	if opts.ResumeFile != "" && opts.SweepFile != "" {
+		// every sweep point would run with the snapshot's settings
+		log.Fatal("a snapshot can't be resumed as part of a sweep")
+	}
+	if opts.ReplayFile != "" && (opts.IsHeadless || opts.SweepFile != "") {
+		log.Fatal("recordings can only be replayed with visualization")
+	}
+	if opts.SweepFile != "" {
+		runSweep(opts, globals)
+	} else if opts.IsHeadless {
+		runHeadless(opts, globals)
+	} else {
+		runGUI(opts, globals)
+	}
 }
 
-func (r *Runner) Update() error {
-	r.handleUserInput()
-	r.sim.Update()
-	r.updateSelected()
-	return nil
+// newSimulation returns a simulation resumed from a snapshot file if one was
+// given in the options, or a newly-generated simulation with the given config
+// if not
+func newSimulation(opts *c.Options, globals *c.Globals) *simulation.Simulation {
+	var sim *simulation.Simulation
+	if opts.ResumeFile == "" {
+		sim = simulation.NewSimulation(opts, globals)
+	} else {
+		var err error
+		if sim, err = simulation.LoadSnapshot(opts, opts.ResumeFile); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Resumed cycle %d with seed %d\n", sim.Cycle(), opts.Seed)
+	}
+	if opts.ChangesFile != "" {
+		changes, err := c.LoadSettingChanges(opts.ChangesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sim.ScheduleSettingChanges(changes)
+	}
+	return sim
 }
 
-func (r *Runner) handleUserInput() {
-	r.ui.HandleUserInput()
+// newRecorder returns a recorder for the simulation if a recording file was
+// given in the options, or nil if not
+func newRecorder(sim *simulation.Simulation, opts *c.Options) *simulation.Recorder {
+	if opts.RecordFile == "" {
+		return nil
+	}
+	recorder, err := simulation.NewRecorder(sim, opts.RecordFile, opts.KeyframeInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return recorder
 }
 
-func (r *Runner) updateSelected() {
-	r.ui.UpdateSelected()
+// recordCycle records the last cycle of a simulation if it is being recorded
+func recordCycle(recorder *simulation.Recorder) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.RecordCycle(); err != nil {
+		log.Printf("failed to record cycle: %v", err)
+	}
 }
 
-func (r *Runner) Draw(screen *ebiten.Image) {
-	r.ui.Render(screen)
-	r.sim.ClearUpdatedPoints()
+// closeRecorder finishes writing a recording if one was made
+func closeRecorder(recorder *simulation.Recorder) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Close(); err != nil {
+		log.Printf("failed to close recording: %v", err)
+	}
 }
 
-func (r *Runner) Layout(_, _ int) (int, int) {
-	return c.ScreenWidth(), c.ScreenHeight()
+// newMetricsWriter returns a metrics writer for the simulation if a metrics
+// file was given in the options, or nil if not
+func newMetricsWriter(sim *simulation.Simulation, opts *c.Options) *simulation.MetricsWriter {
+	if opts.MetricsFile == "" {
+		return nil
+	}
+	metrics, err := simulation.NewMetricsWriter(sim, opts.MetricsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return metrics
 }
 
-func RunSimulation(opts *c.Options) {
-	resources.Init()
+// writeMetrics writes the simulation's metrics if they are being written and
+// are due on the current cycle
+func writeMetrics(metrics *simulation.MetricsWriter) {
+	if metrics == nil {
+		return
+	}
+	if err := metrics.WriteIfDue(); err != nil {
+		log.Printf("failed to write metrics: %v", err)
+	}
+}
 
-	if opts.IsHeadless {
-		sumAllCycles := 0
-		for count := 0; count < opts.TrialCount; count++ {
-			sim := simulation.NewSimulation(opts)
-			start := time.Now()
-			for !sim.IsDone() {
-				sim.Update()
-				if sim.Cycle()%100 == 0 {
-					fmt.Printf("\nCycle: %6d   Organisms: %d   AvgPh: %2.2f", sim.Cycle(), sim.OrganismCount(), sim.AveragePh())
-				}
-			}
-			sumAllCycles += sim.Cycle()
-			elapsed := time.Since(start)
-			fmt.Printf("\nTotal runtime for simulation %d: %s, cycles: %d\n", count, elapsed, sim.Cycle())
-		}
-		avgCycles := sumAllCycles / opts.TrialCount
-		fmt.Printf("\nAverage number of cycles to reach 5000: %d\n", avgCycles)
-	} else {
-		sim := simulation.NewSimulation(opts)
+// closeMetricsWriter finishes writing metrics if any were written
+func closeMetricsWriter(metrics *simulation.MetricsWriter) {
+	if metrics == nil {
+		return
+	}
+	if err := metrics.Close(); err != nil {
+		log.Printf("failed to close metrics: %v", err)
+	}
+}
 
-		ui := ux.NewInterface(sim)
-		gameRunner := &Runner{
-			sim:         sim,
-			ui:          ui,
-			pressedKeys: map[ebiten.Key]bool{},
-		}
+// saveSettingChanges saves every setting change applied to the simulation if
+// any have been applied since the last save, returning the number saved
+func saveSettingChanges(sim *simulation.Simulation, path string, saved int) int {
+	changes := sim.SettingChanges()
+	if path == "" || len(changes) == saved {
+		return saved
+	}
+	if err := c.SaveSettingChanges(path, changes); err != nil {
+		log.Printf("failed to save setting changes: %v", err)
+		return saved
+	}
+	return len(changes)
+}
 
-		ebiten.SetWindowResizable(true)
-		ebiten.SetScreenClearedEveryFrame(false)
-		if err := ebiten.RunGame(gameRunner); err != nil {
-			log.Fatal(err)
-		}
+// saveSnapshotIfDue saves a snapshot of the simulation if the current cycle
+// falls on the snapshot interval given in the options
+func saveSnapshotIfDue(sim *simulation.Simulation) {
+	interval := sim.SnapshotInterval()
+	if interval <= 0 || sim.Cycle() == 0 || sim.Cycle()%interval != 0 {
+		return
+	}
+	if err := sim.SaveSnapshot(sim.SnapshotFile()); err != nil {
+		log.Printf("failed to save snapshot: %v", err)
 	}
 }
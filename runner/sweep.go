@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	c "github.com/Zebbeni/protozoa/config"
+)
+
+// runSweep runs every combination of settings in the sweep file headless,
+// once per seed, and writes one row per run to the sweep results file
+func runSweep(opts *c.Options, globals *c.Globals) {
+	sweep, err := c.LoadSweep(opts.SweepFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	combinations, err := sweep.Combinations(rand.New(rand.NewSource(int64(opts.Seed))))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	trials := make([]trial, 0, len(combinations)*sweep.Seeds)
+	for _, combination := range combinations {
+		combinationGlobals, err := globals.WithSettings(opts.SweepFile, combination)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// every combination uses the same seeds so their results compare fairly
+		for s := 0; s < sweep.Seeds; s++ {
+			trials = append(trials, trial{index: len(trials), seed: opts.Seed + s, globals: combinationGlobals})
+		}
+	}
+	fmt.Printf("Sweeping %d combinations with %d seeds each (%d runs)\n", len(combinations), sweep.Seeds, len(trials))
+
+	start := time.Now()
+	results := runTrials(opts, trials)
+	printSummary(results, time.Since(start))
+
+	if err = writeSweepResults(opts.SweepOutFile, sweep.Keys(), combinations, sweep.Seeds, results); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote sweep results to %s\n", opts.SweepOutFile)
+}
+
+// writeSweepResults writes a CSV table with the settings and outcome of every
+// run in a sweep
+func writeSweepResults(path string, keys []string, combinations []map[string]interface{}, seeds int, results []trialResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create sweep results file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	header := []string{"run", "combination", "seed"}
+	header = append(header, keys...)
+	header = append(header, "cycles", "end_reason", "organisms", "dead", "average_ph", "runtime_seconds")
+	if err = writer.Write(header); err != nil {
+		return err
+	}
+	for _, r := range results {
+		combination := r.trial / seeds
+		row := []string{strconv.Itoa(r.trial), strconv.Itoa(combination), strconv.Itoa(r.seed)}
+		for _, key := range keys {
+			row = append(row, fmt.Sprint(combinations[combination][key]))
+		}
+		row = append(row,
+			strconv.Itoa(r.cycles),
+			r.reason,
+			strconv.Itoa(r.organisms),
+			strconv.Itoa(r.dead),
+			strconv.FormatFloat(r.averagePh, 'f', 4, 64),
+			strconv.FormatFloat(r.runtime.Seconds(), 'f', 3, 64),
+		)
+		if err = writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
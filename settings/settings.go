@@ -0,0 +1,10 @@
+// Package settings embeds the default simulation settings so that they are
+// available wherever the simulation runs, not only from the repository root
+package settings
+
+import _ "embed"
+
+// Default contains the default simulation settings in JSON format
+//
+//go:embed default.json
+var Default []byte
@@ -0,0 +1,291 @@
+// Package sim is a stable API for creating, stepping, inspecting and changing
+// Protozoa simulations from other Go programs. It has no dependency on the
+// user interface, so it can be used wherever Go itself builds.
+//
+// A World is not safe for concurrent use. Accessors and interventions should
+// be called between calls to Step, from the goroutine that calls Step.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/decision"
+	"github.com/Zebbeni/protozoa/event"
+	"github.com/Zebbeni/protozoa/organism"
+	"github.com/Zebbeni/protozoa/simulation"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// Config contains all parameters of a simulation
+type Config = config.Globals
+
+// Point is a location on the simulation grid
+type Point = utils.Point
+
+// Event describes something that happened to an organism during a cycle
+type Event = event.Event
+
+// Observer receives the events reported by a World
+type Observer = event.Observer
+
+// ErrOutOfBounds is returned by interventions at points outside of the grid
+var ErrOutOfBounds = errors.New("point is outside of the grid")
+
+// ErrWall is returned by interventions at points containing a wall
+var ErrWall = errors.New("point contains a wall")
+
+// ErrOccupied is returned when an organism cannot be spawned at a point
+// because it already contains an organism or food
+var ErrOccupied = errors.New("point is occupied")
+
+// Options contains settings that affect how a World runs but not what it
+// contains
+type Options struct {
+	// Seed is the random seed used to generate the world and its organisms
+	Seed int
+	// Deterministic makes worlds with the same seed and config always produce
+	// identical results, at some cost to speed
+	Deterministic bool
+}
+
+// Organism is a read-only copy of an organism's state
+type Organism struct {
+	ID         int
+	AncestorID int
+	Location   Point
+	Health     float64
+	Size       float64
+	Age        int
+	Children   int
+	Action     string
+}
+
+// Food is a read-only copy of a food item
+type Food struct {
+	Location Point
+	Value    int
+}
+
+// World is a single simulation
+type World struct {
+	sim *simulation.Simulation
+}
+
+// DefaultConfig returns the default simulation config
+func DefaultConfig() Config {
+	return config.GetDefaultGlobals()
+}
+
+// New returns a newly-generated World using its own copy of the given config
+func New(cfg Config, opts Options) *World {
+	return &World{sim: simulation.NewSimulation(newOptions(opts), &cfg)}
+}
+
+// Load returns a World restored from a snapshot file saved by Save. The seed
+// and deterministic setting stored in the snapshot are used.
+func Load(path string) (*World, error) {
+	s, err := simulation.LoadSnapshot(newOptions(Options{}), path)
+	if err != nil {
+		return nil, err
+	}
+	return &World{sim: s}, nil
+}
+
+func newOptions(opts Options) *config.Options {
+	return &config.Options{
+		IsHeadless:      true,
+		IsDeterministic: opts.Deterministic,
+		Seed:            opts.Seed,
+	}
+}
+
+// Save writes a snapshot of the World to a file, from which it can be
+// restored with Load
+func (w *World) Save(path string) error {
+	return w.sim.SaveSnapshot(path)
+}
+
+// Step advances the World by n cycles
+func (w *World) Step(n int) {
+	for i := 0; i < n; i++ {
+		w.sim.Update()
+		w.sim.ClearUpdatedPoints()
+	}
+}
+
+// AddObserver registers an observer to be sent all events that occur in the
+// World from now on. Events are sent at the end of each cycle run by Step.
+func (w *World) AddObserver(observer Observer) {
+	w.sim.AddObserver(observer)
+}
+
+// Cycle returns the number of the last cycle run, or -1 if none have run yet
+func (w *World) Cycle() int {
+	return w.sim.Cycle()
+}
+
+// IsDone returns true once any of the end conditions in the World's config
+// has been met
+func (w *World) IsDone() bool {
+	return w.sim.IsDone()
+}
+
+// EndReason describes the end condition that was met, or is empty if the
+// World hasn't ended
+func (w *World) EndReason() string {
+	return w.sim.EndReason()
+}
+
+// Config returns a copy of the World's config
+func (w *World) Config() Config {
+	return *w.sim.Config()
+}
+
+// Width returns the number of grid units across the World
+func (w *World) Width() int {
+	return w.sim.Geometry().Width()
+}
+
+// Height returns the number of grid units down the World
+func (w *World) Height() int {
+	return w.sim.Geometry().Height()
+}
+
+// OrganismCount returns the number of living organisms
+func (w *World) OrganismCount() int {
+	return w.sim.OrganismCount()
+}
+
+// DeadCount returns the number of organisms that have died
+func (w *World) DeadCount() int {
+	return w.sim.GetDeadCount()
+}
+
+// Organisms returns all living organisms, ordered by ID
+func (w *World) Organisms() []Organism {
+	infos := w.sim.GetAllOrganismInfo()
+	organisms := make([]Organism, 0, len(infos))
+	for _, info := range infos {
+		organisms = append(organisms, newOrganism(info))
+	}
+	sort.Slice(organisms, func(i, j int) bool {
+		return organisms[i].ID < organisms[j].ID
+	})
+	return organisms
+}
+
+// Organism returns the living organism with the given ID, and false if none
+// exists
+func (w *World) Organism(id int) (Organism, bool) {
+	info := w.sim.GetOrganismInfoByID(id)
+	if info == nil {
+		return Organism{}, false
+	}
+	return newOrganism(info), true
+}
+
+func newOrganism(info *organism.Info) Organism {
+	return Organism{
+		ID:         info.ID,
+		AncestorID: info.AncestorID,
+		Location:   info.Location,
+		Health:     info.Health,
+		Size:       info.Size,
+		Age:        info.Age,
+		Children:   info.Children,
+		Action:     decision.Map[info.Action],
+	}
+}
+
+// Food returns all food items, ordered by location
+func (w *World) Food() []Food {
+	items := w.sim.GetFoodItems()
+	foods := make([]Food, 0, len(items))
+	for _, item := range items {
+		foods = append(foods, Food{Location: item.Point, Value: item.Value})
+	}
+	sort.Slice(foods, func(i, j int) bool {
+		if foods[i].Location.X != foods[j].Location.X {
+			return foods[i].Location.X < foods[j].Location.X
+		}
+		return foods[i].Location.Y < foods[j].Location.Y
+	})
+	return foods
+}
+
+// Ph returns a copy of the pH map, indexed by x, then y
+func (w *World) Ph() [][]float64 {
+	phMap := w.sim.GetPhMap()
+	ph := make([][]float64, len(phMap))
+	for x := range phMap {
+		ph[x] = make([]float64, len(phMap[x]))
+		copy(ph[x], phMap[x])
+	}
+	return ph
+}
+
+// PhAt returns the pH at a given point
+func (w *World) PhAt(p Point) (float64, error) {
+	if err := w.checkPoint(p); err != nil {
+		return 0, err
+	}
+	return w.sim.GetPhAtPoint(p), nil
+}
+
+// AveragePh returns the average pH across the World
+func (w *World) AveragePh() float64 {
+	return w.sim.AveragePh()
+}
+
+// Walls returns all points containing a wall
+func (w *World) Walls() []Point {
+	return w.sim.GetWalls()
+}
+
+// SpawnOrganism creates a new organism with random traits at a given point
+// and returns its ID
+func (w *World) SpawnOrganism(p Point) (int, error) {
+	if err := w.checkPoint(p); err != nil {
+		return 0, err
+	}
+	id, ok := w.sim.SpawnOrganismAtPoint(p)
+	if !ok {
+		return 0, fmt.Errorf("failed to spawn organism at %v: %w", p, ErrOccupied)
+	}
+	return id, nil
+}
+
+// AddFood adds a value of food at a given point, up to the maximum food value
+// allowed by the config
+func (w *World) AddFood(p Point, value int) error {
+	if err := w.checkPoint(p); err != nil {
+		return err
+	}
+	w.sim.AddFoodAtPoint(p, value)
+	return nil
+}
+
+// ChangePh adds a positive or negative change to the pH at a given point,
+// bounded by the minimum and maximum pH allowed by the config
+func (w *World) ChangePh(p Point, change float64) error {
+	if err := w.checkPoint(p); err != nil {
+		return err
+	}
+	w.sim.AddPhChangeAtPoint(p, change)
+	return nil
+}
+
+// checkPoint returns an error if a point is outside the grid or on a wall
+func (w *World) checkPoint(p Point) error {
+	geometry := w.sim.Geometry()
+	if !p.InBounds(geometry.Width(), geometry.Height()) {
+		return fmt.Errorf("%v: %w", p, ErrOutOfBounds)
+	}
+	if geometry.IsWall(p) {
+		return fmt.Errorf("%v: %w", p, ErrWall)
+	}
+	return nil
+}
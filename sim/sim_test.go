@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.GridUnitsWide = 40
+	cfg.GridUnitsHigh = 30
+	cfg.InitialOrganisms = 0
+	cfg.MinOrganisms = 0
+	cfg.InitialFood = 0
+	cfg.ChanceToAddFoodItem = 0
+	cfg.UsePools = false
+	return cfg
+}
+
+func TestInterventions(t *testing.T) {
+	world := New(testConfig(), Options{Seed: 1, Deterministic: true})
+
+	id, err := world.SpawnOrganism(Point{X: 5, Y: 5})
+	if err != nil {
+		t.Fatalf("failed to spawn organism: %v", err)
+	}
+	if o, found := world.Organism(id); !found || o.Location != (Point{X: 5, Y: 5}) {
+		t.Errorf("expected organism %d at (5, 5), found %+v", id, o)
+	}
+	if _, err = world.SpawnOrganism(Point{X: 5, Y: 5}); !errors.Is(err, ErrOccupied) {
+		t.Errorf("expected ErrOccupied spawning on an organism, got %v", err)
+	}
+	if _, err = world.SpawnOrganism(Point{X: 40, Y: 0}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("expected ErrOutOfBounds spawning outside the grid, got %v", err)
+	}
+
+	if err = world.AddFood(Point{X: 10, Y: 10}, 20); err != nil {
+		t.Fatalf("failed to add food: %v", err)
+	}
+	if food := world.Food(); len(food) != 1 || food[0].Value != 20 {
+		t.Errorf("expected one food item of value 20, found %+v", food)
+	}
+
+	before, _ := world.PhAt(Point{X: 20, Y: 20})
+	if err = world.ChangePh(Point{X: 20, Y: 20}, -0.5); err != nil {
+		t.Fatalf("failed to change pH: %v", err)
+	}
+	if after, _ := world.PhAt(Point{X: 20, Y: 20}); after != before-0.5 {
+		t.Errorf("expected pH %f after change, found %f", before-0.5, after)
+	}
+
+	// changes to returned maps must not affect the world
+	ph := world.Ph()
+	ph[0][0] = -100
+	if p, _ := world.PhAt(Point{X: 0, Y: 0}); p == -100 {
+		t.Error("expected Ph to return a copy of the pH map")
+	}
+}
+
+func TestStep(t *testing.T) {
+	cfg := testConfig()
+	cfg.InitialOrganisms = 20
+	world := New(cfg, Options{Seed: 3, Deterministic: true})
+
+	world.Step(10)
+	if world.Cycle() != 9 {
+		t.Errorf("expected last cycle 9 after 10 steps, found %d", world.Cycle())
+	}
+	if len(world.Organisms()) != world.OrganismCount() {
+		t.Errorf("expected %d organisms, found %d", world.OrganismCount(), len(world.Organisms()))
+	}
+}
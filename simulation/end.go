@@ -0,0 +1,80 @@
+package simulation
+
+import "fmt"
+
+// EndState tracks the end conditions given in a simulation's config. It is
+// saved in snapshots so resumed simulations end on the same cycle.
+type EndState struct {
+	// number of consecutive cycles the population has been above or below
+	// the configured thresholds
+	CyclesAbove, CyclesBelow int
+
+	Reason string
+}
+
+// checkEndConditions records the reason the simulation should end if any end
+// condition was met on the last cycle
+func (s *Simulation) checkEndConditions() {
+	if s.end.Reason != "" {
+		return
+	}
+	s.end.Reason = s.findEndReason()
+}
+
+func (s *Simulation) findEndReason() string {
+	cfg := s.cfg
+	population := s.OrganismCount()
+	if population == 0 {
+		return "no organisms left"
+	}
+	if cfg.EndMaxCycles > 0 && s.cycle+1 >= cfg.EndMaxCycles {
+		return fmt.Sprintf("reached %d cycles", cfg.EndMaxCycles)
+	}
+
+	requiredCycles := cfg.EndPopulationCycles
+	if requiredCycles < 1 {
+		requiredCycles = 1
+	}
+	s.end.CyclesAbove = countCycles(s.end.CyclesAbove, cfg.EndPopulationAbove > 0 && population > cfg.EndPopulationAbove)
+	if s.end.CyclesAbove >= requiredCycles {
+		return fmt.Sprintf("population above %d for %d cycles", cfg.EndPopulationAbove, requiredCycles)
+	}
+	s.end.CyclesBelow = countCycles(s.end.CyclesBelow, cfg.EndPopulationBelow > 0 && population < cfg.EndPopulationBelow)
+	if s.end.CyclesBelow >= requiredCycles {
+		return fmt.Sprintf("population below %d for %d cycles", cfg.EndPopulationBelow, requiredCycles)
+	}
+
+	if cfg.EndLineageDominance > 0 {
+		ancestorID, count := s.organismManager.LargestLineage()
+		if share := float64(count) / float64(population); share >= cfg.EndLineageDominance {
+			return fmt.Sprintf("lineage of ancestor %d reached %.0f%% of organisms", ancestorID, share*100)
+		}
+	}
+
+	if cfg.EndMinAveragePh != 0 || cfg.EndMaxAveragePh != 0 {
+		averagePh := s.AveragePh()
+		if averagePh < cfg.EndMinAveragePh || averagePh > cfg.EndMaxAveragePh {
+			return fmt.Sprintf("average pH %.2f left %.2f-%.2f", averagePh, cfg.EndMinAveragePh, cfg.EndMaxAveragePh)
+		}
+	}
+	return ""
+}
+
+// countCycles returns the number of consecutive cycles a condition has held
+func countCycles(count int, holds bool) int {
+	if holds {
+		return count + 1
+	}
+	return 0
+}
+
+// IsDone returns true once any end condition has been met
+func (s *Simulation) IsDone() bool {
+	return s.end.Reason != ""
+}
+
+// EndReason describes the end condition that was met, or is empty if the
+// simulation hasn't ended
+func (s *Simulation) EndReason() string {
+	return s.end.Reason
+}
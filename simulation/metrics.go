@@ -0,0 +1,148 @@
+package simulation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Zebbeni/protozoa/manager"
+)
+
+// Metrics returns statistics about the current state of the simulation,
+// always in the same order. Births and deaths are counted since the given
+// totals of organisms created and organisms dead.
+func (s *Simulation) Metrics(previousCreated, previousDead int) []manager.Metric {
+	metrics := []manager.Metric{
+		{Name: "cycle", Value: float64(s.cycle)},
+		{Name: "organisms", Value: float64(s.OrganismCount())},
+		{Name: "births", Value: float64(s.organismManager.TotalCreated() - previousCreated)},
+		{Name: "deaths", Value: float64(s.GetDeadCount() - previousDead)},
+		{Name: "average_ph", Value: s.AveragePh()},
+		{Name: "food_count", Value: float64(s.foodManager.FoodCount())},
+		{Name: "food_value", Value: float64(s.foodManager.TotalValue())},
+	}
+	return append(metrics, s.organismManager.Metrics()...)
+}
+
+// MetricsWriter writes the simulation's metrics to a CSV or JSON Lines file
+// every PopulationUpdateInterval cycles
+type MetricsWriter struct {
+	sim    *Simulation
+	file   *os.File
+	writer *bufio.Writer
+	isCSV  bool
+
+	wroteHeader           bool
+	lastCreated, lastDead int
+}
+
+// NewMetricsWriter creates a metrics file, written as CSV if its name ends in
+// .csv, or as JSON Lines if it ends in .jsonl or .json
+func NewMetricsWriter(sim *Simulation, path string) (*MetricsWriter, error) {
+	var isCSV bool
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		isCSV = true
+	case ".jsonl", ".json":
+		isCSV = false
+	default:
+		return nil, fmt.Errorf("metrics file %s must end in .csv, .jsonl or .json", path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	return &MetricsWriter{
+		sim:         sim,
+		file:        file,
+		writer:      bufio.NewWriter(file),
+		isCSV:       isCSV,
+		lastCreated: sim.organismManager.TotalCreated(),
+		lastDead:    sim.GetDeadCount(),
+	}, nil
+}
+
+// WriteIfDue writes the simulation's metrics if the current cycle falls on
+// the population update interval
+func (w *MetricsWriter) WriteIfDue() error {
+	if w.sim.cycle%w.sim.cfg.PopulationUpdateInterval != 0 {
+		return nil
+	}
+	return w.Write()
+}
+
+// Write writes the simulation's current metrics
+func (w *MetricsWriter) Write() error {
+	metrics := w.sim.Metrics(w.lastCreated, w.lastDead)
+	w.lastCreated = w.sim.organismManager.TotalCreated()
+	w.lastDead = w.sim.GetDeadCount()
+
+	var err error
+	if w.isCSV {
+		err = w.writeCSV(metrics)
+	} else {
+		err = w.writeJSON(metrics)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+func (w *MetricsWriter) writeCSV(metrics []manager.Metric) error {
+	csvWriter := csv.NewWriter(w.writer)
+	if !w.wroteHeader {
+		header := make([]string, len(metrics))
+		for i, metric := range metrics {
+			header[i] = metric.Name
+		}
+		if err := csvWriter.Write(header); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	row := make([]string, len(metrics))
+	for i, metric := range metrics {
+		row[i] = formatMetric(metric.Value)
+	}
+	if err := csvWriter.Write(row); err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeJSON writes metrics as a single JSON object, keeping them in order
+func (w *MetricsWriter) writeJSON(metrics []manager.Metric) error {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, metric := range metrics {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.Quote(metric.Name))
+		sb.WriteString(":")
+		sb.WriteString(formatMetric(metric.Value))
+	}
+	sb.WriteString("}\n")
+	_, err := w.writer.WriteString(sb.String())
+	return err
+}
+
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Close flushes all metrics written and closes the file
+func (w *MetricsWriter) Close() error {
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return w.file.Close()
+}
@@ -0,0 +1,369 @@
+package simulation
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/food"
+	"github.com/Zebbeni/protozoa/manager"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// RecordingVersion is incremented whenever the recording format changes in a
+// way that older recordings can no longer be replayed
+const RecordingVersion = 1
+
+// RecordingHeader begins every recording with a snapshot of the simulation on
+// the cycle recording started
+type RecordingHeader struct {
+	Version  int
+	Snapshot Snapshot
+}
+
+// PhChange is the pH recorded at a single point
+type PhChange struct {
+	Point utils.Point
+	Ph    float64
+}
+
+// Frame contains everything that changed in a simulation during one cycle.
+// Some frames also contain a keyframe with the full state of the simulation
+// at the end of the cycle, so replays can seek without applying every frame
+// before it.
+type Frame struct {
+	Cycle     int
+	Keyframe  *SnapshotState
+	Organisms manager.OrganismChanges
+	Food      []food.Item
+	Ph        []PhChange
+	AveragePh float64
+	Settings  []config.SettingChange
+}
+
+// Recorder writes a simulation's changes to a file on every cycle, so the
+// simulation can be replayed later without running it again
+type Recorder struct {
+	sim              *Simulation
+	file             *os.File
+	zipWriter        *gzip.Writer
+	encoder          *gob.Encoder
+	keyframeInterval int
+
+	living          map[int]bool
+	ancestorCount   int
+	changesRecorded int
+}
+
+// NewRecorder creates a recording file beginning with a snapshot of the
+// simulation's current state. A keyframe is written every keyframeInterval
+// cycles, or never if it is 0.
+func NewRecorder(sim *Simulation, path string, keyframeInterval int) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	r := &Recorder{
+		sim:              sim,
+		file:             file,
+		zipWriter:        gzip.NewWriter(file),
+		keyframeInterval: keyframeInterval,
+		living:           make(map[int]bool),
+	}
+	r.encoder = gob.NewEncoder(r.zipWriter)
+
+	header := RecordingHeader{Version: RecordingVersion, Snapshot: *sim.Snapshot()}
+	if err = r.encoder.Encode(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+	for _, o := range header.Snapshot.Organisms.Organisms {
+		r.living[o.ID] = true
+	}
+	r.ancestorCount = len(header.Snapshot.Organisms.OriginalAncestors)
+	r.changesRecorded = len(sim.SettingChanges())
+	return r, nil
+}
+
+// RecordCycle writes everything that changed during the last cycle. It should
+// be called after every Update, before the simulation's updated points are
+// cleared.
+func (r *Recorder) RecordCycle() error {
+	frame := Frame{Cycle: r.sim.Cycle(), AveragePh: r.sim.AveragePh()}
+	r.recordOrganisms(&frame)
+	r.recordFood(&frame)
+	r.recordPh(&frame)
+	r.recordSettings(&frame)
+	if r.keyframeInterval > 0 && frame.Cycle%r.keyframeInterval == 0 {
+		state := r.sim.Snapshot().SnapshotState
+		frame.Keyframe = &state
+	}
+	if err := r.encoder.Encode(frame); err != nil {
+		return fmt.Errorf("failed to record cycle %d: %w", frame.Cycle, err)
+	}
+	if frame.Keyframe != nil {
+		// keep everything up to the latest keyframe replayable if the
+		// recording is interrupted
+		return r.zipWriter.Flush()
+	}
+	return nil
+}
+
+func (r *Recorder) recordOrganisms(frame *Frame) {
+	organisms := r.sim.organismManager
+	changes := organisms.Changes()
+	living := make(map[int]bool, len(changes))
+	for _, c := range changes {
+		living[c.ID] = true
+		if r.living[c.ID] {
+			frame.Organisms.Organisms = append(frame.Organisms.Organisms, c)
+		} else if state, found := organisms.OrganismState(c.ID); found {
+			frame.Organisms.Spawned = append(frame.Organisms.Spawned, state)
+		}
+	}
+	for id := range r.living {
+		if !living[id] {
+			frame.Organisms.Died = append(frame.Organisms.Died, id)
+		}
+	}
+	sort.Ints(frame.Organisms.Died)
+	r.living = living
+
+	frame.Organisms.Ancestors = organisms.AncestorsSince(r.ancestorCount)
+	r.ancestorCount += len(frame.Organisms.Ancestors)
+}
+
+func (r *Recorder) recordFood(frame *Frame) {
+	for _, point := range sortedPoints(r.sim.GetUpdatedFoodPoints()) {
+		value := 0
+		if item, found := r.sim.GetFoodAtPoint(point); found {
+			value = item.Value
+		}
+		frame.Food = append(frame.Food, food.Item{Point: point, Value: value})
+	}
+}
+
+func (r *Recorder) recordPh(frame *Frame) {
+	for _, point := range sortedPoints(r.sim.GetUpdatedPhPoints()) {
+		frame.Ph = append(frame.Ph, PhChange{Point: point, Ph: r.sim.GetPhAtPoint(point)})
+	}
+}
+
+// recordSettings records the setting changes applied during the last cycle
+func (r *Recorder) recordSettings(frame *Frame) {
+	changes := r.sim.SettingChanges()
+	frame.Settings = changes[r.changesRecorded:]
+	r.changesRecorded = len(changes)
+}
+
+// Close finishes writing the recording
+func (r *Recorder) Close() error {
+	if err := r.zipWriter.Close(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("failed to finish recording: %w", err)
+	}
+	return r.file.Close()
+}
+
+// sortedPoints returns the points in a map ordered by X, then Y
+func sortedPoints(pointMap map[string]utils.Point) []utils.Point {
+	points := make([]utils.Point, 0, len(pointMap))
+	for _, point := range pointMap {
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		return points[i].Y < points[j].Y
+	})
+	return points
+}
+
+// Replay plays back a recording by applying each recorded frame to a
+// simulation, without choosing or resolving any organism actions
+type Replay struct {
+	path      string
+	sim       *Simulation
+	header    RecordingHeader
+	lastCycle int
+
+	file      *os.File
+	zipReader *gzip.Reader
+	decoder   *gob.Decoder
+}
+
+// OpenReplay opens a recording and returns a Replay positioned at the cycle
+// recording started
+func OpenReplay(options *config.Options, path string) (*Replay, error) {
+	r := &Replay{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	sim, err := NewSimulationFromSnapshot(options, &r.header.Snapshot)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	r.sim = sim
+
+	// read through every frame once to find where the recording ends
+	r.lastCycle = r.header.Snapshot.Cycle
+	for {
+		frame, err := r.nextFrame()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.lastCycle = frame.Cycle
+	}
+	if err = r.rewind(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the recording file and reads its header
+func (r *Replay) open() error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	zipReader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+	r.file, r.zipReader = file, zipReader
+	r.decoder = gob.NewDecoder(zipReader)
+
+	if err = r.decoder.Decode(&r.header); err != nil {
+		r.Close()
+		return fmt.Errorf("failed to read recording header: %w", err)
+	}
+	if r.header.Version != RecordingVersion {
+		r.Close()
+		return fmt.Errorf("unsupported recording version %d (expected %d)", r.header.Version, RecordingVersion)
+	}
+	return nil
+}
+
+// rewind reopens the recording and restores the simulation to the cycle
+// recording started
+func (r *Replay) rewind() error {
+	r.Close()
+	if err := r.open(); err != nil {
+		return err
+	}
+	*r.sim.cfg = r.header.Snapshot.Globals
+	r.sim.restoreTimeline(r.header.Snapshot.SnapshotHeader)
+	return r.sim.restoreState(r.header.Snapshot.Cycle, r.header.Snapshot.SnapshotState)
+}
+
+func (r *Replay) nextFrame() (*Frame, error) {
+	frame := &Frame{}
+	if err := r.decoder.Decode(frame); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return frame, nil
+}
+
+// Simulation returns the simulation the recording is played back on
+func (r *Replay) Simulation() *Simulation {
+	return r.sim
+}
+
+// FirstCycle returns the cycle recording started on
+func (r *Replay) FirstCycle() int {
+	return r.header.Snapshot.Cycle
+}
+
+// LastCycle returns the last cycle in the recording
+func (r *Replay) LastCycle() int {
+	return r.lastCycle
+}
+
+// Step applies the next frame of the recording, returning false if the end of
+// the recording has been reached
+func (r *Replay) Step() (bool, error) {
+	frame, err := r.nextFrame()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, r.applyFrame(frame, false)
+}
+
+// Seek moves the replay to the given cycle, or as close to it as the recording
+// allows
+func (r *Replay) Seek(cycle int) error {
+	if cycle < r.sim.Cycle() {
+		if err := r.rewind(); err != nil {
+			return err
+		}
+	}
+	for r.sim.Cycle() < cycle {
+		frame, err := r.nextFrame()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = r.applyFrame(frame, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFrame applies the changes recorded in a frame, or restores its keyframe
+// instead if it has one and useKeyframe is true
+func (r *Replay) applyFrame(frame *Frame, useKeyframe bool) error {
+	// settings changed by the timeline aren't recorded, since the timeline
+	// gives them again
+	r.sim.cycle = frame.Cycle
+	r.sim.applyTimeline()
+	for _, change := range frame.Settings {
+		if err := r.sim.cfg.SetSetting(change.Key, change.Value); err != nil {
+			return fmt.Errorf("failed to replay cycle %d: %w", frame.Cycle, err)
+		}
+	}
+	if useKeyframe && frame.Keyframe != nil {
+		return r.sim.restoreState(frame.Cycle, *frame.Keyframe)
+	}
+	for _, item := range frame.Food {
+		r.sim.foodManager.SetFoodAtPoint(item.Point, item.Value)
+	}
+	for _, change := range frame.Ph {
+		r.sim.environmentManager.SetPhAtPoint(change.Point, change.Ph)
+	}
+	r.sim.environmentManager.SetAveragePh(frame.AveragePh)
+	// fields other than pH aren't recorded, since they change the same way
+	// every time they are updated
+	r.sim.environmentManager.UpdateFields()
+	if err := r.sim.organismManager.ApplyChanges(frame.Organisms); err != nil {
+		return fmt.Errorf("failed to replay cycle %d: %w", frame.Cycle, err)
+	}
+	return nil
+}
+
+// Close closes the recording file
+func (r *Replay) Close() {
+	if r.zipReader != nil {
+		r.zipReader.Close()
+	}
+	if r.file != nil {
+		r.file.Close()
+	}
+}
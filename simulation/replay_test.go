@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/manager"
+)
+
+func TestReplayMatchesRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.recording")
+	sim := NewSimulation(&config.Options{Seed: 11, IsDeterministic: true}, &testGlobals)
+	recorder, err := NewRecorder(sim, path, 20)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	// the organisms and food expected on each recorded cycle
+	type recorded struct {
+		Organisms    interface{}
+		Food         manager.FoodManagerState
+		GrowthFactor float64
+	}
+	expected := make(map[int]recorded)
+	for i := 0; i < 50; i++ {
+		if i == 25 {
+			sim.ChangeSetting("growth_factor", 0.8)
+		}
+		sim.Update()
+		if err = recorder.RecordCycle(); err != nil {
+			t.Fatalf("failed to record cycle %d: %v", sim.Cycle(), err)
+		}
+		sim.ClearUpdatedPoints()
+		expected[sim.Cycle()] = recorded{sim.organismManager.Changes(), sim.foodManager.State(), sim.cfg.GrowthFactor}
+	}
+	if err = recorder.Close(); err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	replay, err := OpenReplay(&config.Options{}, path)
+	if err != nil {
+		t.Fatalf("failed to open replay: %v", err)
+	}
+	defer replay.Close()
+	if replay.FirstCycle() != -1 || replay.LastCycle() != 49 {
+		t.Fatalf("expected cycles -1 to 49, found %d to %d", replay.FirstCycle(), replay.LastCycle())
+	}
+
+	check := func() {
+		replayed := replay.Simulation()
+		want := expected[replayed.Cycle()]
+		got := recorded{replayed.organismManager.Changes(), replayed.foodManager.State(), replayed.cfg.GrowthFactor}
+		got.Food.RandomState = want.Food.RandomState
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("replay differs from recording on cycle %d", replayed.Cycle())
+		}
+	}
+	for {
+		more, err := replay.Step()
+		if err != nil {
+			t.Fatalf("failed to step replay: %v", err)
+		}
+		if !more {
+			break
+		}
+		check()
+	}
+
+	for _, cycle := range []int{10, 45, 3, 20, 49} {
+		if err = replay.Seek(cycle); err != nil {
+			t.Fatalf("failed to seek to cycle %d: %v", cycle, err)
+		}
+		if replay.Simulation().Cycle() != cycle {
+			t.Fatalf("expected cycle %d after seeking, found %d", cycle, replay.Simulation().Cycle())
+		}
+		check()
+	}
+}
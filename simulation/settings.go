@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"log"
+	"sort"
+
+	"github.com/Zebbeni/protozoa/config"
+)
+
+// ChangeSetting requests a change to a numeric setting. Changes are applied at
+// the beginning of the next cycle, so no cycle runs with a mix of old and new
+// settings. A later request for the same setting replaces an earlier one.
+func (s *Simulation) ChangeSetting(key string, value float64) {
+	for i := range s.pendingChanges {
+		if s.pendingChanges[i].Key == key {
+			s.pendingChanges[i].Value = value
+			return
+		}
+	}
+	s.pendingChanges = append(s.pendingChanges, config.SettingChange{Key: key, Value: value})
+}
+
+// PendingSetting returns the value a setting will be changed to at the
+// beginning of the next cycle, and false if no change is pending
+func (s *Simulation) PendingSetting(key string) (float64, bool) {
+	for _, change := range s.pendingChanges {
+		if change.Key == key {
+			return change.Value, true
+		}
+	}
+	return 0, false
+}
+
+// ScheduleSettingChanges schedules changes to be applied at the beginning of
+// the cycles given for them. Changes scheduled for cycles that have already
+// run are ignored, as are changes already scheduled, so a simulation resumed
+// from a snapshot can be given the same changes it was first run with.
+func (s *Simulation) ScheduleSettingChanges(changes []config.SettingChange) {
+	for _, change := range changes {
+		if !s.isScheduled(change) {
+			s.scheduledChanges = append(s.scheduledChanges, change)
+		}
+	}
+	sort.SliceStable(s.scheduledChanges, func(i, j int) bool {
+		return s.scheduledChanges[i].Cycle < s.scheduledChanges[j].Cycle
+	})
+}
+
+func (s *Simulation) isScheduled(change config.SettingChange) bool {
+	for _, scheduled := range s.scheduledChanges {
+		if scheduled == change {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingChanges returns every setting change applied so far, in the order
+// they were applied. Scheduling these on a new simulation with the same seed
+// reproduces a run.
+func (s *Simulation) SettingChanges() []config.SettingChange {
+	return s.settingChanges
+}
+
+// applySettingChanges applies every change scheduled for the current cycle,
+// followed by any requested since the last cycle
+func (s *Simulation) applySettingChanges() {
+	for len(s.scheduledChanges) > 0 && s.scheduledChanges[0].Cycle <= s.cycle {
+		change := s.scheduledChanges[0]
+		s.scheduledChanges = s.scheduledChanges[1:]
+		if change.Cycle == s.cycle {
+			s.applySettingChange(change)
+		}
+	}
+	for _, change := range s.pendingChanges {
+		change.Cycle = s.cycle
+		s.applySettingChange(change)
+	}
+	s.pendingChanges = nil
+}
+
+func (s *Simulation) applySettingChange(change config.SettingChange) {
+	if err := s.cfg.SetSetting(change.Key, change.Value); err != nil {
+		log.Printf("cycle %d: failed to change %s: %v", s.cycle, change.Key, err)
+		return
+	}
+	s.settingChanges = append(s.settingChanges, change)
+}
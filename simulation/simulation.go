@@ -1,13 +1,14 @@
 package simulation
 
 import (
-	"fmt"
 	d "github.com/Zebbeni/protozoa/decision"
 	"image/color"
 	"sort"
 	"time"
 
 	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/environment"
+	"github.com/Zebbeni/protozoa/event"
 	"github.com/Zebbeni/protozoa/food"
 	"github.com/Zebbeni/protozoa/manager"
 	"github.com/Zebbeni/protozoa/organism"
@@ -16,7 +17,9 @@ import (
 
 // Simulation contains a list of forces, particles, and drawing settings
 type Simulation struct {
-	options *config.Options
+	options  *config.Options
+	cfg      *config.Globals
+	geometry *utils.Geometry
 
 	cycle    int
 	isPaused bool
@@ -28,24 +31,47 @@ type Simulation struct {
 	environmentManager *manager.EnvironmentManager
 	updateManager      *manager.UpdateManager
 
+	observers []event.Observer
+	end       EndState
+
+	// setting changes requested, scheduled and applied while running
+	pendingChanges   []config.SettingChange
+	scheduledChanges []config.SettingChange
+	settingChanges   []config.SettingChange
+
+	// timelineBase holds the values settings changed by the timeline return
+	// to, and timelineValues the values it last gave them
+	timelineBase   map[string]float64
+	timelineValues map[string]float64
+	activeRegimes  []string
+
 	// debug statistics
 	UpdateTime, EnvironmentUpdateTime, FoodUpdateTime, OrganismUpdateTime time.Duration
 	OrganismUpdateLoopTime, OrganismResolveLoopTime                       time.Duration
 }
 
-// NewSimulation returns a simulation with generated world and organisms
+// NewSimulation returns a simulation with generated world and organisms, using
+// its own copy of the given config.
 // cycle increments at the beginning of Update() so start at -1 to ensure
 // first actions are attributed to cycle 0
-func NewSimulation(options *config.Options) *Simulation {
+func NewSimulation(options *config.Options, globals *config.Globals) *Simulation {
+	cfg := *globals
 	sim := &Simulation{
-		options:  options,
-		cycle:    -1,
-		isPaused: false,
+		options:      options,
+		cfg:          &cfg,
+		geometry:     utils.NewGeometry(&cfg),
+		cycle:        -1,
+		isPaused:     false,
+		timelineBase: newTimelineBase(&cfg),
 	}
+	// each manager draws from its own stream so that one never affects the
+	// random values seen by another
+	random := utils.NewRandom(int64(options.Seed))
+	foodRandom, organismRandom, environmentRandom := random.NewStream(), random.NewStream(), random.NewStream()
 	sim.updateManager = manager.NewUpdateManager()
-	sim.environmentManager = manager.NewEnvironmentManager(sim)
-	sim.foodManager = manager.NewFoodManager(sim)
-	sim.organismManager = manager.NewOrganismManager(sim)
+	sim.environmentManager = manager.NewEnvironmentManager(sim, environmentRandom)
+	sim.foodManager = manager.NewFoodManager(sim, foodRandom)
+	sim.organismManager = manager.NewOrganismManager(sim, organismRandom, options.IsDeterministic)
 
 	return sim
 }
@@ -55,17 +81,51 @@ func (s *Simulation) Update() {
 	if s.isPaused {
 		return
 	}
+	s.step()
+}
+
+// Step runs the next n cycles of the simulation, even while it is paused
+func (s *Simulation) Step(n int) {
+	for i := 0; i < n; i++ {
+		s.step()
+	}
+}
 
+func (s *Simulation) step() {
 	s.cycle++
 	start := time.Now()
 
+	s.applyTimeline()
+	s.applySettingChanges()
+
 	s.updateEnvironment()
 	s.updateFood()
 	s.updateOrganisms()
+	s.notifyObservers()
+	s.checkEndConditions()
 
 	s.UpdateTime = time.Since(start)
 }
 
+// AddObserver registers an observer to be sent all events that occur in the
+// simulation from now on. Events are sent at the end of each Update, from the
+// goroutine that called it.
+func (s *Simulation) AddObserver(observer event.Observer) {
+	s.observers = append(s.observers, observer)
+	s.organismManager.RecordEvents(true)
+}
+
+func (s *Simulation) notifyObservers() {
+	if len(s.observers) == 0 {
+		return
+	}
+	for _, e := range s.organismManager.TakeEvents() {
+		for _, observer := range s.observers {
+			observer.OnEvent(e)
+		}
+	}
+}
+
 func (s *Simulation) updateEnvironment() {
 	start := time.Now()
 	s.environmentManager.Update()
@@ -86,13 +146,20 @@ func (s *Simulation) updateOrganisms() {
 	s.OrganismResolveLoopTime = s.organismManager.ResolveDuration
 }
 
-// IsDone returns true if end condition met
-func (s *Simulation) IsDone() bool {
-	if s.GetNumOrganisms() == 0 {
-		fmt.Printf("\nSimulation ended on cycle %d with %d organisms alive.", s.cycle, config.MaxOrganisms())
-		return true
-	}
-	return false
+// Config returns the parameters this simulation is running with
+func (s *Simulation) Config() *config.Globals {
+	return s.cfg
+}
+
+// Geometry returns the dimensions and walls of the simulation grid
+func (s *Simulation) Geometry() *utils.Geometry {
+	return s.geometry
+}
+
+// IsDeterministic returns true if the simulation processes organisms in a
+// fixed order, making runs with the same seed reproducible
+func (s *Simulation) IsDeterministic() bool {
+	return s.options.IsDeterministic
 }
 
 // IsDebug returns true if debug flag set on run
@@ -105,6 +172,17 @@ func (s *Simulation) ToggleDebug() {
 	s.options.IsDebugging = s.options.IsDebugging == false
 }
 
+// SnapshotFile returns the file path snapshots of this simulation are saved to
+func (s *Simulation) SnapshotFile() string {
+	return s.options.SnapshotFile
+}
+
+// SnapshotInterval returns the number of cycles between automatic snapshots
+// (0 if disabled)
+func (s *Simulation) SnapshotInterval() int {
+	return s.options.SnapshotInterval
+}
+
 // Cycle returns the current simulation cycle number
 func (s *Simulation) Cycle() int {
 	return s.cycle
@@ -248,6 +326,12 @@ func (s *Simulation) AveragePh() float64 {
 	return s.environmentManager.GetAveragePh()
 }
 
+// SpawnOrganismAtPoint creates a new organism with random traits at a given
+// point and returns its ID, or false if the point is not empty
+func (s *Simulation) SpawnOrganismAtPoint(point utils.Point) (int, bool) {
+	return s.organismManager.SpawnRandomOrganismAt(point)
+}
+
 // GetFoodAtPoint returns the value of any food at a given point and whether
 // a food item actually exists there.
 func (s *Simulation) GetFoodAtPoint(point utils.Point) (*food.Item, bool) {
@@ -293,6 +377,38 @@ func (s *Simulation) GetWalls() []utils.Point {
 	return s.environmentManager.GetWalls()
 }
 
+// GetFieldAtPoint returns the current value of a field at a given location,
+// where field is the index of the field in the config
+func (s *Simulation) GetFieldAtPoint(field int, point utils.Point) float64 {
+	return s.environmentManager.GetFieldAtPoint(field, point)
+}
+
+// GetFieldMap returns the current values of a field across the grid
+func (s *Simulation) GetFieldMap(field int) [][]float64 {
+	return s.environmentManager.GetFieldMap(field)
+}
+
+// GetAverageField returns the average value of a field across the grid
+func (s *Simulation) GetAverageField(field int) float64 {
+	return s.environmentManager.GetAverageField(field)
+}
+
+// GetLightAtPoint returns the light shining on a given location this cycle,
+// before any shade from organisms
+func (s *Simulation) GetLightAtPoint(point utils.Point) float64 {
+	return s.environmentManager.GetLightAtPoint(point)
+}
+
+// GetDaylight returns the fraction of noon light shining this cycle
+func (s *Simulation) GetDaylight() float64 {
+	return s.environmentManager.GetDaylight()
+}
+
+// GetVents returns all vents placed in the environment
+func (s *Simulation) GetVents() []environment.Vent {
+	return s.environmentManager.GetVents()
+}
+
 // GetPhAtPoint returns the current Ph of the environment at a given location
 func (s *Simulation) GetPhAtPoint(point utils.Point) float64 {
 	return s.environmentManager.GetPhAtPoint(point)
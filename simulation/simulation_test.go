@@ -0,0 +1,577 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/event"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+var testGlobals config.Globals
+
+func TestMain(m *testing.M) {
+	testGlobals = config.GetDefaultGlobals()
+	testGlobals.GridUnitsWide = 40
+	testGlobals.GridUnitsHigh = 30
+	testGlobals.InitialOrganisms = 100
+	testGlobals.InitialFood = 50
+	os.Exit(m.Run())
+}
+
+func TestDeterministicRunsMatch(t *testing.T) {
+	first := NewSimulation(&config.Options{Seed: 7, IsDeterministic: true}, &testGlobals)
+	second := NewSimulation(&config.Options{Seed: 7, IsDeterministic: true}, &testGlobals)
+	for i := 0; i < 100; i++ {
+		first.Update()
+		second.Update()
+		if !statesMatch(first, second) {
+			t.Fatalf("simulations with the same seed diverged on cycle %d", first.Cycle())
+		}
+	}
+}
+
+// statesMatch returns true if two simulations have identical snapshots
+func statesMatch(a, b *Simulation) bool {
+	first, _ := json.Marshal(a.Snapshot())
+	second, _ := json.Marshal(b.Snapshot())
+	return bytes.Equal(first, second)
+}
+
+func TestSimulationsUseTheirOwnConfig(t *testing.T) {
+	small := testGlobals
+	small.GridUnitsWide = 20
+	small.GridUnitsHigh = 10
+	large := testGlobals
+	large.GridUnitsWide = 60
+	large.GridUnitsHigh = 50
+
+	smallSim := NewSimulation(&config.Options{Seed: 3}, &small)
+	largeSim := NewSimulation(&config.Options{Seed: 3}, &large)
+	for i := 0; i < 20; i++ {
+		smallSim.Update()
+		largeSim.Update()
+	}
+
+	if width := len(smallSim.GetPhMap()); width != 20 {
+		t.Errorf("small simulation pH map was %d wide, expected 20", width)
+	}
+	if width := len(largeSim.GetPhMap()); width != 60 {
+		t.Errorf("large simulation pH map was %d wide, expected 60", width)
+	}
+	for _, info := range largeSim.GetAllOrganismInfo() {
+		if !info.Location.InBounds(60, 50) {
+			t.Errorf("organism %d was outside the large grid at %v", info.ID, info.Location)
+		}
+	}
+}
+
+func TestObserverEventsMatchPopulation(t *testing.T) {
+	sim := NewSimulation(&config.Options{Seed: 5, IsDeterministic: true}, &testGlobals)
+	counts := make(map[event.Type]int)
+	lastCycle := 0
+	locations := make(map[int]utils.Point)
+	for id, info := range sim.GetAllOrganismInfo() {
+		locations[id] = info.Location
+	}
+	sim.AddObserver(event.ObserverFunc(func(e event.Event) {
+		counts[e.Type]++
+		if e.Cycle < lastCycle {
+			t.Errorf("received event from cycle %d after cycle %d", e.Cycle, lastCycle)
+		}
+		lastCycle = e.Cycle
+		switch e.Type {
+		case event.Died:
+			if e.Cause == event.CauseNone {
+				t.Errorf("organism %d died without a cause", e.OrganismID)
+			}
+		case event.Spawned, event.Moved:
+			locations[e.OrganismID] = e.Location
+		case event.Attacked, event.Fed:
+			// the target should be whichever organism was at the target
+			// point when its health changed
+			if location, found := locations[e.TargetID]; !found || location != e.Target {
+				t.Errorf("organism %d at %v was named as the target at %v", e.TargetID, location, e.Target)
+			}
+		}
+	}))
+
+	start := sim.OrganismCount()
+	for i := 0; i < 100; i++ {
+		sim.Update()
+	}
+	if counts[event.Died] == 0 || counts[event.Moved] == 0 || counts[event.Attacked]+counts[event.Fed] == 0 {
+		t.Fatalf("expected deaths, moves and attacks or feeds in 100 cycles, found %v", counts)
+	}
+	if start+counts[event.Spawned]-counts[event.Died] != sim.OrganismCount() {
+		t.Errorf("expected %d + %d spawned - %d died organisms, found %d",
+			start, counts[event.Spawned], counts[event.Died], sim.OrganismCount())
+	}
+}
+
+func TestStepWhilePaused(t *testing.T) {
+	sim := NewSimulation(&config.Options{Seed: 2}, &testGlobals)
+	sim.Pause(true)
+	sim.Update()
+	if sim.Cycle() != -1 {
+		t.Fatalf("expected paused simulation not to update, found cycle %d", sim.Cycle())
+	}
+	sim.Step(1)
+	sim.Step(5)
+	if sim.Cycle() != 5 || !sim.IsPaused() {
+		t.Errorf("expected paused simulation on cycle 5 after stepping, found cycle %d", sim.Cycle())
+	}
+}
+
+func TestEndConditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(g *config.Globals)
+		reason string
+	}{
+		{"max cycles", func(g *config.Globals) { g.EndMaxCycles = 30 }, "reached 30 cycles"},
+		{"population above", func(g *config.Globals) {
+			g.EndPopulationAbove = 10
+			g.EndPopulationCycles = 3
+		}, "population above 10 for 3 cycles"},
+		{"population below", func(g *config.Globals) { g.EndPopulationBelow = 1000 }, "population below 1000 for 1 cycles"},
+		{"ph band", func(g *config.Globals) {
+			g.EndMinAveragePh = 9
+			g.EndMaxAveragePh = 10
+		}, "average pH"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			globals := testGlobals
+			test.modify(&globals)
+			sim := NewSimulation(&config.Options{Seed: 4, IsDeterministic: true}, &globals)
+			for i := 0; i < 100 && !sim.IsDone(); i++ {
+				sim.Update()
+			}
+			if !strings.HasPrefix(sim.EndReason(), test.reason) {
+				t.Errorf("expected simulation to end with %q, found %q", test.reason, sim.EndReason())
+			}
+		})
+	}
+}
+
+func TestResumedSimulationEndsOnSameCycle(t *testing.T) {
+	globals := testGlobals
+	globals.EndPopulationAbove = 10
+	globals.EndPopulationCycles = 30
+	options := config.Options{Seed: 4, IsDeterministic: true}
+	original := NewSimulation(&options, &globals)
+	for !original.IsDone() {
+		original.Update()
+	}
+
+	interrupted := NewSimulation(&options, &globals)
+	interrupted.Step(20)
+	resumed, err := NewSimulationFromSnapshot(&config.Options{}, interrupted.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !resumed.IsDone() && resumed.Cycle() < original.Cycle() {
+		resumed.Update()
+	}
+	if resumed.Cycle() != original.Cycle() || resumed.EndReason() != original.EndReason() {
+		t.Errorf("expected resumed simulation to end on cycle %d with %q, found cycle %d with %q",
+			original.Cycle(), original.EndReason(), resumed.Cycle(), resumed.EndReason())
+	}
+}
+
+func TestSettingChangesReproduceRun(t *testing.T) {
+	live := NewSimulation(&config.Options{Seed: 6, IsDeterministic: true}, &testGlobals)
+	live.Step(20)
+	live.ChangeSetting("chance_to_add_food_item", 0.5)
+	live.ChangeSetting("health_change_inflicted_by_attack", -3)
+	live.ChangeSetting("chance_to_add_food_item", 0.25)
+	live.Step(30)
+
+	changes := live.SettingChanges()
+	if len(changes) != 2 || changes[0].Cycle != 20 || changes[0].Value != 0.25 {
+		t.Fatalf("expected two changes applied on cycle 20, found %v", changes)
+	}
+	if live.Config().HealthChangeInflictedByAttack != -3 {
+		t.Errorf("expected attack damage of -3, found %f", live.Config().HealthChangeInflictedByAttack)
+	}
+
+	rerun := NewSimulation(&config.Options{Seed: 6, IsDeterministic: true}, &testGlobals)
+	rerun.ScheduleSettingChanges(changes)
+	rerun.Step(50)
+	if !statesMatch(live, rerun) {
+		t.Errorf("expected scheduled changes to reproduce the live run")
+	}
+
+	// changes still to come are kept when resuming from a snapshot
+	original := NewSimulation(&config.Options{Seed: 6, IsDeterministic: true}, &testGlobals)
+	original.ScheduleSettingChanges([]config.SettingChange{{Cycle: 35, Key: "chance_to_add_food_item", Value: 0.5}})
+	original.Step(25)
+	original.ChangeSetting("health_change_inflicted_by_attack", -3)
+	var saved bytes.Buffer
+	if err := original.WriteSnapshot(&saved); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ReadSnapshot(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := NewSimulationFromSnapshot(&config.Options{}, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original.Step(25)
+	resumed.Step(25)
+	if !reflect.DeepEqual(resumed.SettingChanges(), original.SettingChanges()) || len(resumed.SettingChanges()) != 2 {
+		t.Errorf("expected resumed simulation to apply changes %v, found %v", original.SettingChanges(), resumed.SettingChanges())
+	}
+	if !statesMatch(original, resumed) {
+		t.Errorf("expected resumed simulation to match the original")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	globals := testGlobals
+	globals.ChanceToAddFoodItem = 0.1
+	globals.Timeline = []config.Regime{
+		{Name: "winter", Period: 20, Duration: 10, Settings: map[string]float64{"chance_to_add_food_item": 0.01}},
+		{Name: "tide", Start: 30, Waves: []config.Wave{{Key: "ph_diffuse_factor", Shape: config.WaveSquare, Period: 4, Min: 0.02, Max: 0.04}}},
+	}
+	sim := NewSimulation(&config.Options{Seed: 8}, &globals)
+	expectFood := func(cycle int, chance float64, regimes string) {
+		sim.Step(cycle - sim.Cycle())
+		if sim.Config().ChanceToAddFoodItem != chance {
+			t.Errorf("cycle %d: expected food chance %f, found %f", cycle, chance, sim.Config().ChanceToAddFoodItem)
+		}
+		if active := strings.Join(sim.ActiveRegimes(), ","); active != regimes {
+			t.Errorf("cycle %d: expected regimes %q, found %q", cycle, regimes, active)
+		}
+	}
+	expectFood(0, 0.01, "winter")
+	expectFood(9, 0.01, "winter")
+	expectFood(10, 0.1, "")
+	expectFood(25, 0.01, "winter")
+	expectFood(31, 0.1, "tide")
+	if sim.Config().PhDiffuseFactor != 0.04 {
+		t.Errorf("expected the square wave to be high on cycle 31, found %f", sim.Config().PhDiffuseFactor)
+	}
+
+	// a simulation resumed during a regime keeps settings changed by hand
+	// until the timeline next moves them, then returns to the base settings
+	sim.Step(9)
+	sim.ChangeSetting("chance_to_add_food_item", 0.05)
+	sim.Step(1)
+	resumed, err := NewSimulationFromSnapshot(&config.Options{}, sim.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.Step(1)
+	if resumed.Config().ChanceToAddFoodItem != 0.05 || strings.Join(resumed.ActiveRegimes(), ",") != "winter,tide" {
+		t.Errorf("expected food chance 0.05 set by hand after resuming, found %f with regimes %v", resumed.Config().ChanceToAddFoodItem, resumed.ActiveRegimes())
+	}
+	resumed.Step(8)
+	if resumed.Cycle() != 50 || resumed.Config().ChanceToAddFoodItem != 0.1 {
+		t.Errorf("expected food chance 0.1 on cycle 50 after resuming, found %f on %d", resumed.Config().ChanceToAddFoodItem, resumed.Cycle())
+	}
+}
+
+func TestWallMapKeepsWorldOffWalls(t *testing.T) {
+	globals := testGlobals
+	globals.GridUnitsWide, globals.GridUnitsHigh = 100, 60
+	globals.WallMap = "../settings/maps/corridors.txt"
+	globals.InitialOrganisms, globals.InitialFood = 500, 500
+	sim := NewSimulation(&config.Options{Seed: 9}, &globals)
+	sim.Step(50)
+
+	if len(sim.GetWalls()) == 0 {
+		t.Fatal("expected walls from the wall map")
+	}
+	for _, info := range sim.GetAllOrganismInfo() {
+		if sim.Geometry().IsWall(info.Location) {
+			t.Errorf("organism %d is on a wall at %v", info.ID, info.Location)
+		}
+	}
+	for _, item := range sim.GetFoodItems() {
+		if sim.Geometry().IsWall(item.Point) {
+			t.Errorf("food is on a wall at %v", item.Point)
+		}
+	}
+}
+
+func TestVentsKeepPhAroundThem(t *testing.T) {
+	globals := testGlobals
+	globals.InitialOrganisms = 0
+	globals.Vents = []config.Vent{
+		{X: 5, Y: 5, Strength: 0.5, Radius: 3},
+		{Random: true, Count: 3, Strength: -0.5, Radius: 2},
+	}
+	sim := NewSimulation(&config.Options{Seed: 3}, &globals)
+	sim.Step(100)
+
+	vents := sim.GetVents()
+	if len(vents) != 4 {
+		t.Fatalf("expected 4 vents, found %d", len(vents))
+	}
+	neutral := (globals.MinInitialPh + globals.MaxInitialPh) / 2
+	for _, vent := range vents {
+		ph := sim.GetPhAtPoint(vent.Point)
+		if vent.IsSource() && ph <= neutral+1 || !vent.IsSource() && ph >= neutral-1 {
+			t.Errorf("expected the vent at %v to push pH away from %g, found %g", vent.Point, neutral, ph)
+		}
+	}
+
+	restored, err := NewSimulationFromSnapshot(&config.Options{Seed: 3}, sim.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Step(1)
+	sim.Step(1)
+	if !statesMatch(sim, restored) {
+		t.Error("expected a restored simulation to keep its vents")
+	}
+}
+
+func TestFields(t *testing.T) {
+	temperature := config.Field{
+		Name: "temperature", Min: 0, Max: 100, MinInitial: 10, MaxInitial: 30, DiffuseFactor: 0.1,
+		Landscape: config.Landscape{Generator: config.LandscapeGradient},
+		MinIdeal:  0, MaxIdeal: 0, MaxTolerance: 5,
+		HealthChangePerUnhealthy: -100,
+	}
+	globals := testGlobals
+	globals.Fields = []config.Field{temperature}
+	globals.Vents = []config.Vent{{Field: "temperature", X: 20, Y: 15, Strength: 5, Radius: 2}}
+	sim := NewSimulation(&config.Options{Seed: 8}, &globals)
+
+	left, right := sim.GetFieldAtPoint(0, utils.Point{X: 0, Y: 3}), sim.GetFieldAtPoint(0, utils.Point{X: 39, Y: 3})
+	if left != 10 || right != 30 {
+		t.Errorf("expected temperature to rise from 10 to 30 left to right, found %g to %g", left, right)
+	}
+	for _, info := range sim.GetAllOrganismInfo() {
+		if traits, _ := sim.GetOrganismTraitsByID(info.ID); len(traits.FieldTraits) != 1 {
+			t.Fatalf("expected organism %d to have traits for 1 field, found %d", info.ID, len(traits.FieldTraits))
+		}
+	}
+	metrics := make(map[string]float64)
+	for _, metric := range sim.Metrics(0, 0) {
+		metrics[metric.Name] = metric.Value
+	}
+	if tolerance, ok := metrics["temperature_tolerance_mean"]; !ok || tolerance <= 0 || tolerance > 5 {
+		t.Errorf("expected a mean temperature tolerance from 0 to 5 in metrics, found %g", tolerance)
+	}
+	if _, ok := metrics["ideal_temperature_stddev"]; !ok {
+		t.Errorf("expected the ideal temperature's standard deviation in metrics")
+	}
+
+	start := sim.OrganismCount()
+	causes := make(map[event.Cause]int)
+	sim.AddObserver(event.ObserverFunc(func(e event.Event) {
+		if e.Type == event.Died {
+			causes[e.Cause]++
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "fields.recording")
+	recorder, err := NewRecorder(sim, path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		sim.Update()
+		if err = recorder.RecordCycle(); err != nil {
+			t.Fatal(err)
+		}
+		sim.ClearUpdatedPoints()
+	}
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if causes[event.CauseEnvironment] != start {
+		t.Errorf("expected every organism to die from temperature, found deaths %v", causes)
+	}
+	if vent := sim.GetFieldAtPoint(0, utils.Point{X: 20, Y: 15}); vent < 60 {
+		t.Errorf("expected the vent to heat its location well above 20, found %g", vent)
+	}
+
+	replay, err := OpenReplay(&config.Options{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replay.Close()
+	// seek back before the last keyframe and forward again to check that
+	// fields are updated while replaying
+	for _, cycle := range []int{29, 7, 29} {
+		if err = replay.Seek(cycle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(replay.Simulation().GetFieldMap(0), sim.GetFieldMap(0)) {
+		t.Errorf("expected the replayed temperature to match the recording")
+	}
+}
+
+func TestLight(t *testing.T) {
+	globals := testGlobals
+	globals.LightLandscape = config.Landscape{Generator: config.LandscapeGradient, Angle: 90, Invert: true}
+	globals.MinLight, globals.MaxLight = 0, 2
+	globals.DayLength, globals.NightLight = 100, 0.2
+	sim := NewSimulation(&config.Options{Seed: 2}, &globals)
+
+	sim.Step(1)
+	top, bottom := sim.GetLightAtPoint(utils.Point{X: 4, Y: 0}), sim.GetLightAtPoint(utils.Point{X: 4, Y: 29})
+	if math.Abs(top-2) > 1e-9 || math.Abs(bottom) > 1e-9 {
+		t.Errorf("expected light to fade from 2 at the top to 0 at the bottom at noon, found %g to %g", top, bottom)
+	}
+	sim.Step(50)
+	if daylight := sim.GetDaylight(); math.Abs(daylight-0.2) > 1e-9 {
+		t.Errorf("expected night light of 0.2 at midnight, found %g", daylight)
+	}
+
+	// organisms in the dark can't gain health from chemosynthesis, so the same
+	// run ends with less health than a lit one
+	totalHealth := func(minLight, maxLight float64) float64 {
+		globals := testGlobals
+		globals.MinLight, globals.MaxLight = minLight, maxLight
+		sim := NewSimulation(&config.Options{Seed: 2, IsDeterministic: true}, &globals)
+		sim.Step(1)
+		total := 0.0
+		for _, info := range sim.GetAllOrganismInfo() {
+			total += info.Health
+		}
+		return total
+	}
+	if lit, dark := totalHealth(1, 1), totalHealth(0, 0); dark >= lit {
+		t.Errorf("expected less health in the dark than in light, found %g and %g", dark, lit)
+	}
+}
+
+func TestBoundedWorld(t *testing.T) {
+	globals := testGlobals
+	globals.InitialOrganisms = 0
+	globals.PhDiffuseFactor = 0.5
+	globals.InitialPhLandscape = config.Landscape{Generator: config.LandscapeGradient}
+	edgeChange := func(bounded bool) float64 {
+		globals.BoundedWorld = bounded
+		sim := NewSimulation(&config.Options{Seed: 4}, &globals)
+		edge := utils.Point{X: 0, Y: 10}
+		before := sim.GetPhAtPoint(edge)
+		sim.Step(1)
+		return math.Abs(sim.GetPhAtPoint(edge) - before)
+	}
+	// the lowest pH, on the left edge, only meets the highest pH on the right
+	// edge when the world wraps around
+	if bounded, wrapped := edgeChange(true), edgeChange(false); bounded > 0.1 || wrapped < 0.5 {
+		t.Errorf("expected pH to diffuse across the edge only when wrapping, found changes of %g bounded and %g wrapped", bounded, wrapped)
+	}
+
+	globals = testGlobals
+	globals.BoundedWorld = true
+	globals.InitialOrganisms = 400
+	sim := NewSimulation(&config.Options{Seed: 4}, &globals)
+	geometry := sim.Geometry()
+	width, height := globals.GridUnitsWide, globals.GridUnitsHigh
+	// stepping off an edge gives a wall beyond it, not the opposite edge
+	for _, c := range []struct{ from, step, expected utils.Point }{
+		{utils.Point{X: 0, Y: 5}, utils.Point{X: -1, Y: 0}, utils.Point{X: -1, Y: 5}},
+		{utils.Point{X: width - 1, Y: 5}, utils.Point{X: 1, Y: 0}, utils.Point{X: width, Y: 5}},
+		{utils.Point{X: 5, Y: 0}, utils.Point{X: 0, Y: -1}, utils.Point{X: 5, Y: -1}},
+		{utils.Point{X: 5, Y: height - 1}, utils.Point{X: 0, Y: 1}, utils.Point{X: 5, Y: height}},
+	} {
+		next := geometry.Add(c.from, c.step)
+		if next != c.expected || geometry.InBounds(next) || !geometry.IsWall(next) {
+			t.Errorf("expected stepping %v from %v to give the wall at %v, found %v", c.step, c.from, c.expected, next)
+		}
+	}
+	moved := 0
+	sim.AddObserver(event.ObserverFunc(func(e event.Event) {
+		if e.Type == event.Moved {
+			moved++
+		}
+	}))
+	for cycle := 0; cycle < 100; cycle++ {
+		sim.Step(1)
+		for _, info := range sim.GetAllOrganismInfo() {
+			if !geometry.InBounds(info.Location) {
+				t.Fatalf("cycle %d: organism %d is outside the world at %v", sim.Cycle(), info.ID, info.Location)
+			}
+		}
+		for _, item := range sim.GetFoodItems() {
+			if !geometry.InBounds(item.Point) {
+				t.Fatalf("cycle %d: food is outside the world at %v", sim.Cycle(), item.Point)
+			}
+		}
+	}
+	if moved == 0 {
+		t.Error("expected organisms to move")
+	}
+}
+
+func TestEightDirections(t *testing.T) {
+	globals := testGlobals
+	globals.EightDirections = true
+	globals.InitialOrganisms = 400
+	sim := NewSimulation(&config.Options{Seed: 6}, &globals)
+	geometry := sim.Geometry()
+
+	diagonal := 0
+	sim.AddObserver(event.ObserverFunc(func(e event.Event) {
+		if e.Type != event.Moved {
+			return
+		}
+		for _, step := range utils.EightDirections {
+			if geometry.Add(e.From, step) == e.Location && step.IsDiagonal() {
+				diagonal++
+			}
+		}
+	}))
+	sim.Step(100)
+	if diagonal == 0 {
+		t.Error("expected organisms to move diagonally")
+	}
+	turns := 0
+	for _, metric := range sim.Metrics(0, 0) {
+		if metric.Name == "action_turn_half_left" || metric.Name == "action_turn_half_right" {
+			turns++
+		}
+	}
+	if turns != 2 {
+		t.Error("expected metrics to count organisms turning 45 degrees")
+	}
+
+	for _, direction := range utils.EightDirections {
+		if direction.HalfLeft().HalfRight() != direction || direction.HalfRight().HalfRight() != direction.Right() {
+			t.Errorf("expected turning %v by 45 degrees twice to turn it by 90 degrees", direction)
+		}
+	}
+
+	// on a checkerboard of walls every open point is only reachable by
+	// cutting the corners of walls, so no organism can move
+	var checkerboard strings.Builder
+	for y := 0; y < globals.GridUnitsHigh; y++ {
+		for x := 0; x < globals.GridUnitsWide; x++ {
+			if (x+y)%2 == 0 {
+				checkerboard.WriteString(".")
+			} else {
+				checkerboard.WriteString("#")
+			}
+		}
+		checkerboard.WriteString("\n")
+	}
+	globals.WallMap = filepath.Join(t.TempDir(), "checkerboard.txt")
+	if err := os.WriteFile(globals.WallMap, []byte(checkerboard.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sim = NewSimulation(&config.Options{Seed: 6}, &globals)
+	sim.AddObserver(event.ObserverFunc(func(e event.Event) {
+		if e.Type == event.Moved {
+			t.Errorf("organism %d cut the corner of a wall moving from %v to %v", e.OrganismID, e.From, e.Location)
+		}
+	}))
+	sim.Step(100)
+}
@@ -0,0 +1,242 @@
+package simulation
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Zebbeni/protozoa/config"
+	"github.com/Zebbeni/protozoa/manager"
+	"github.com/Zebbeni/protozoa/utils"
+)
+
+// SnapshotVersion is incremented whenever the snapshot format changes in a way
+// that older snapshots can no longer be loaded
+const SnapshotVersion = 1
+
+// SnapshotHeader contains the version of a snapshot along with the settings
+// needed to recreate the simulation it was taken from
+type SnapshotHeader struct {
+	Version       int
+	Cycle         int
+	Seed          int
+	Deterministic bool
+	Globals       config.Globals
+	// TimelineBase holds the values settings changed by the timeline return
+	// to when no regime changes them
+	TimelineBase map[string]float64
+	// TimelineValues holds the values the timeline last gave settings, so
+	// settings changed by hand since keep their values after resuming
+	TimelineValues map[string]float64
+	ActiveRegimes  []string
+	// End holds the progress made toward the config's end conditions
+	End EndState
+	// setting changes requested for the next cycle, scheduled for later
+	// cycles and applied so far
+	PendingChanges   []config.SettingChange
+	ScheduledChanges []config.SettingChange
+	SettingChanges   []config.SettingChange
+}
+
+// SnapshotState contains the full state of every manager in a simulation
+type SnapshotState struct {
+	Organisms   manager.OrganismManagerState
+	Food        manager.FoodManagerState
+	Environment manager.EnvironmentManagerState
+}
+
+// Snapshot contains everything needed to resume a simulation from the cycle
+// it was taken on
+type Snapshot struct {
+	SnapshotHeader
+	SnapshotState
+}
+
+// Snapshot returns the full current state of the simulation. This should only
+// be called between cycles, while no Update is running.
+func (s *Simulation) Snapshot() *Snapshot {
+	return &Snapshot{
+		SnapshotHeader: SnapshotHeader{
+			Version:       SnapshotVersion,
+			Cycle:         s.cycle,
+			Seed:          s.options.Seed,
+			Deterministic: s.options.IsDeterministic,
+			Globals:       *s.cfg,
+			TimelineBase:  s.timelineBase,
+			End:           s.end,
+
+			TimelineValues: copyValues(s.timelineValues),
+			ActiveRegimes:  s.activeRegimes,
+
+			// copied, since the simulation keeps appending to them
+			PendingChanges:   append([]config.SettingChange(nil), s.pendingChanges...),
+			ScheduledChanges: append([]config.SettingChange(nil), s.scheduledChanges...),
+			SettingChanges:   append([]config.SettingChange(nil), s.settingChanges...),
+		},
+		SnapshotState: SnapshotState{
+			Organisms:   s.organismManager.State(),
+			Food:        s.foodManager.State(),
+			Environment: s.environmentManager.State(),
+		},
+	}
+}
+
+// WriteSnapshot writes a gzipped snapshot of the simulation to w, beginning
+// with the snapshot header so it can be read without decoding the full state
+func (s *Simulation) WriteSnapshot(w io.Writer) error {
+	snapshot := s.Snapshot()
+
+	zipWriter := gzip.NewWriter(w)
+	encoder := json.NewEncoder(zipWriter)
+	if err := encoder.Encode(snapshot.SnapshotHeader); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+	if err := encoder.Encode(snapshot.SnapshotState); err != nil {
+		return fmt.Errorf("failed to write snapshot state: %w", err)
+	}
+	return zipWriter.Close()
+}
+
+// SaveSnapshot writes a snapshot of the simulation to the given file path,
+// replacing any existing file only once the new snapshot is fully written
+func (s *Simulation) SaveSnapshot(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = s.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadSnapshot reads a full snapshot written by WriteSnapshot
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	zipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer zipReader.Close()
+
+	snapshot := &Snapshot{}
+	decoder := json.NewDecoder(zipReader)
+	if err = decodeSnapshotHeader(decoder, &snapshot.SnapshotHeader); err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(&snapshot.SnapshotState); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot state: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ReadSnapshotHeader reads only the header of a snapshot file, which contains
+// the settings the snapshot's simulation was running with
+func ReadSnapshotHeader(path string) (*SnapshotHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	zipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer zipReader.Close()
+
+	header := &SnapshotHeader{}
+	if err = decodeSnapshotHeader(json.NewDecoder(zipReader), header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func decodeSnapshotHeader(decoder *json.Decoder, header *SnapshotHeader) error {
+	if err := decoder.Decode(header); err != nil {
+		return fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if header.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d (expected %d)", header.Version, SnapshotVersion)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot file and returns a simulation restored to the
+// cycle it was taken on, using the settings stored in the snapshot header.
+func LoadSnapshot(options *config.Options, path string) (*Simulation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	snapshot, err := ReadSnapshot(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewSimulationFromSnapshot(options, snapshot)
+}
+
+// NewSimulationFromSnapshot returns a simulation restored from a snapshot, which
+// will continue from the cycle after the one it was taken on. Only
+// deterministic simulations continue exactly as they would have if never
+// stopped, since the order other simulations resolve actions in can vary.
+// The seed and deterministic setting of the given options are replaced by
+// those the snapshot was taken with.
+func NewSimulationFromSnapshot(options *config.Options, snapshot *Snapshot) (*Simulation, error) {
+	options.Seed = snapshot.Seed
+	options.IsDeterministic = options.IsDeterministic || snapshot.Deterministic
+
+	cfg := snapshot.Globals
+	sim := &Simulation{
+		options:      options,
+		cfg:          &cfg,
+		geometry:     utils.NewGeometry(&cfg),
+		isPaused:     false,
+		timelineBase: snapshot.TimelineBase,
+		end:          snapshot.End,
+
+		pendingChanges:   snapshot.PendingChanges,
+		scheduledChanges: snapshot.ScheduledChanges,
+		settingChanges:   snapshot.SettingChanges,
+	}
+	sim.restoreTimeline(snapshot.SnapshotHeader)
+	if sim.timelineBase == nil {
+		sim.timelineBase = newTimelineBase(&cfg)
+	}
+	sim.updateManager = manager.NewUpdateManager()
+	if err := sim.restoreState(snapshot.Cycle, snapshot.SnapshotState); err != nil {
+		return nil, err
+	}
+	return sim, nil
+}
+
+// restoreState replaces the state of every manager with a previously-saved
+// state, leaving the simulation's config and observers unchanged
+func (s *Simulation) restoreState(cycle int, state SnapshotState) error {
+	environmentManager, err := manager.RestoreEnvironmentManager(s, state.Environment)
+	if err != nil {
+		return err
+	}
+	foodManager := manager.RestoreFoodManager(s, state.Food)
+	organismManager, err := manager.RestoreOrganismManager(s, state.Organisms, s.options.IsDeterministic)
+	if err != nil {
+		return err
+	}
+	organismManager.RecordEvents(len(s.observers) > 0)
+
+	s.cycle = cycle
+	s.environmentManager = environmentManager
+	s.foodManager = foodManager
+	s.organismManager = organismManager
+	return nil
+}
@@ -0,0 +1,99 @@
+package simulation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Zebbeni/protozoa/config"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	sim := NewSimulation(&config.Options{Seed: 1, IsDeterministic: true}, &testGlobals)
+	for i := 0; i < 50; i++ {
+		sim.Update()
+	}
+
+	var saved bytes.Buffer
+	if err := sim.WriteSnapshot(&saved); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	snapshot, err := ReadSnapshot(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if snapshot.Cycle != sim.Cycle() {
+		t.Errorf("snapshot cycle was %d, expected %d", snapshot.Cycle, sim.Cycle())
+	}
+
+	resumed, err := NewSimulationFromSnapshot(&config.Options{Seed: 1, IsDeterministic: true}, snapshot)
+	if err != nil {
+		t.Fatalf("failed to restore simulation: %v", err)
+	}
+	if resumed.OrganismCount() != sim.OrganismCount() {
+		t.Errorf("resumed simulation had %d organisms, expected %d", resumed.OrganismCount(), sim.OrganismCount())
+	}
+
+	if !statesMatch(sim, resumed) {
+		t.Errorf("resumed simulation state did not match the original")
+	}
+
+	// a resumed deterministic simulation should continue exactly as the
+	// original does
+	for i := 0; i < 50; i++ {
+		sim.Update()
+		resumed.Update()
+	}
+	if !statesMatch(sim, resumed) {
+		t.Errorf("resumed simulation diverged from the original by cycle %d", sim.Cycle())
+	}
+}
+
+func TestResumedRunMatchesUninterruptedRun(t *testing.T) {
+	uninterrupted := NewSimulation(&config.Options{Seed: 3, IsDeterministic: true}, &testGlobals)
+	interrupted := NewSimulation(&config.Options{Seed: 3, IsDeterministic: true}, &testGlobals)
+	for i := 0; i < 50; i++ {
+		uninterrupted.Update()
+		interrupted.Update()
+	}
+
+	path := filepath.Join(t.TempDir(), "run.snapshot")
+	if err := interrupted.SaveSnapshot(path); err != nil {
+		t.Fatalf("failed to save snapshot: %v", err)
+	}
+	// the snapshot gives the seed and mode the run was started with
+	resumed, err := LoadSnapshot(&config.Options{}, path)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		uninterrupted.Update()
+		resumed.Update()
+		if !statesMatch(uninterrupted, resumed) {
+			t.Fatalf("resumed run diverged from the uninterrupted run on cycle %d", resumed.Cycle())
+		}
+	}
+}
+
+func TestSnapshotRejectsOtherVersions(t *testing.T) {
+	snapshot := NewSimulation(&config.Options{Seed: 1}, &testGlobals).Snapshot()
+	snapshot.Version = SnapshotVersion + 1
+
+	var saved bytes.Buffer
+	zipWriter := gzip.NewWriter(&saved)
+	encoder := json.NewEncoder(zipWriter)
+	if err := encoder.Encode(snapshot.SnapshotHeader); err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.Encode(snapshot.SnapshotState); err != nil {
+		t.Fatal(err)
+	}
+	zipWriter.Close()
+
+	if _, err := ReadSnapshot(&saved); err == nil {
+		t.Errorf("expected a version %d snapshot to be rejected", snapshot.Version)
+	}
+}
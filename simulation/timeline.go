@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"log"
+
+	"github.com/Zebbeni/protozoa/config"
+)
+
+// newTimelineBase returns the values of every setting changed by the config's
+// timeline, which they return to while no regime changes them
+func newTimelineBase(cfg *config.Globals) map[string]float64 {
+	base := make(map[string]float64)
+	for _, key := range cfg.TimelineKeys() {
+		base[key], _ = cfg.Setting(key)
+	}
+	return base
+}
+
+// applyTimeline changes settings to the values the timeline gives them on the
+// current cycle. A setting is only changed when its timeline value changes, so
+// settings changed by hand keep their new value until the timeline next moves
+// them. Timeline values are checked against their tunable ranges when the
+// config is validated, so the rest of the config isn't validated again here.
+func (s *Simulation) applyTimeline() {
+	if len(s.cfg.Timeline) == 0 {
+		return
+	}
+	values, active := s.cfg.TimelineValues(s.cycle, s.timelineBase)
+	s.activeRegimes = active
+	if s.timelineValues == nil {
+		s.timelineValues = make(map[string]float64, len(values))
+	}
+	for key, value := range values {
+		if previous, ok := s.timelineValues[key]; ok && previous == value {
+			continue
+		}
+		s.timelineValues[key] = value
+		if current, _ := s.cfg.Setting(key); current == value {
+			continue
+		}
+		if err := s.cfg.SetTunable(key, value); err != nil {
+			log.Printf("cycle %d: failed to apply timeline to %s: %v", s.cycle, key, err)
+		}
+	}
+}
+
+// ActiveRegimes returns the names of the timeline regimes active on the
+// current cycle
+func (s *Simulation) ActiveRegimes() []string {
+	return s.activeRegimes
+}
+
+// restoreTimeline sets the timeline values last applied and the active
+// regimes to those saved in a snapshot header
+func (s *Simulation) restoreTimeline(header SnapshotHeader) {
+	s.timelineValues = copyValues(header.TimelineValues)
+	s.activeRegimes = header.ActiveRegimes
+}
+
+// copyValues returns a copy of a map of setting values, or nil if it is nil
+func copyValues(values map[string]float64) map[string]float64 {
+	if values == nil {
+		return nil
+	}
+	copied := make(map[string]float64, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
@@ -18,10 +18,14 @@ func (p Point) String() string {
 }
 
 var (
-	directionUp    = Point{X: 0, Y: -1}
-	directionRight = Point{X: +1, Y: 0}
-	directionDown  = Point{X: 0, Y: +1}
-	directionLeft  = Point{X: -1, Y: 0}
+	directionUp        = Point{X: 0, Y: -1}
+	directionUpRight   = Point{X: +1, Y: -1}
+	directionRight     = Point{X: +1, Y: 0}
+	directionDownRight = Point{X: +1, Y: +1}
+	directionDown      = Point{X: 0, Y: +1}
+	directionDownLeft  = Point{X: -1, Y: +1}
+	directionLeft      = Point{X: -1, Y: 0}
+	directionUpLeft    = Point{X: -1, Y: -1}
 	// Directions is a list of all possible directions
 	// to travel on the simulation grid
 	Directions = [...]Point{
@@ -30,26 +34,28 @@ var (
 		directionDown,
 		directionLeft,
 	}
+	// EightDirections is a list of all possible directions to travel on a
+	// grid that allows diagonal movement, in clockwise order
+	EightDirections = [...]Point{
+		directionUp,
+		directionUpRight,
+		directionRight,
+		directionDownRight,
+		directionDown,
+		directionDownLeft,
+		directionLeft,
+		directionUpLeft,
+	}
 )
 
 // GetRandomPoint returns a random point somewhere on the simulation grid
-func GetRandomPoint(width, height int) Point {
+func GetRandomPoint(r *rand.Rand, width, height int) Point {
 	return Point{
-		X: rand.Intn(width),
-		Y: rand.Intn(height),
+		X: r.Intn(width),
+		Y: r.Intn(height),
 	}
 }
 
-// GetRandomDirection returns a point representing a random direction
-func GetRandomDirection() Point {
-	return Directions[rand.Intn(len(Directions))]
-}
-
-// Add add a given Point and returns the result
-func (p Point) Add(toAdd Point) Point {
-	return Point{X: p.X + toAdd.X, Y: p.Y + toAdd.Y}.Wrap()
-}
-
 // Times multiplies a given value and returns the result
 func (p *Point) Times(toMultiply int) Point {
 	return Point{
@@ -58,30 +64,140 @@ func (p *Point) Times(toMultiply int) Point {
 	}
 }
 
+// InBounds returns true if the point lies within a grid of the given size
+func (p Point) InBounds(width, height int) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < width && p.Y < height
+}
+
+// Geometry contains the dimensions and walls of a simulation grid, as given
+// by the config of the simulation it belongs to
+type Geometry struct {
+	width, height         int
+	usePools              bool
+	poolWidth, poolHeight int
+	wallMap               *c.WallMap
+	// bounded grids have hard edges instead of wrapping around
+	bounded bool
+	// eight-direction grids let organisms face and move diagonally
+	eightDirections bool
+}
+
+// NewGeometry returns the Geometry of a grid described by the given config.
+// The config's wall map, if any, should already have been validated, since it
+// panics if the map can't be loaded.
+func NewGeometry(cfg *c.Globals) *Geometry {
+	g := &Geometry{
+		width:           cfg.GridUnitsWide,
+		height:          cfg.GridUnitsHigh,
+		usePools:        cfg.UsePools,
+		poolWidth:       cfg.PoolWidth,
+		poolHeight:      cfg.PoolHeight,
+		bounded:         cfg.BoundedWorld,
+		eightDirections: cfg.EightDirections,
+	}
+	if cfg.WallMap != "" {
+		wallMap, err := c.LoadWallMap(cfg.WallMap)
+		if err != nil {
+			panic(err)
+		}
+		if wallMap.Width != g.width || wallMap.Height != g.height {
+			panic(fmt.Sprintf("wall map %s is %d x %d, but the grid is %d x %d", cfg.WallMap, wallMap.Width, wallMap.Height, g.width, g.height))
+		}
+		g.wallMap = wallMap
+	}
+	return g
+}
+
+// Width returns the number of grid units across the grid
+func (g *Geometry) Width() int { return g.width }
+
+// Height returns the number of grid units down the grid
+func (g *Geometry) Height() int { return g.height }
+
+// Add adds two Points and returns the result, wrapped around the grid. On a
+// bounded grid the result isn't wrapped, so it may lie outside of the grid,
+// where IsWall treats it as a wall.
+func (g *Geometry) Add(p, toAdd Point) Point {
+	sum := Point{X: p.X + toAdd.X, Y: p.Y + toAdd.Y}
+	if g.bounded {
+		return sum
+	}
+	return g.Wrap(sum)
+}
+
+// IsBounded returns true if the grid has hard edges instead of wrapping
+func (g *Geometry) IsBounded() bool { return g.bounded }
+
+// HasEightDirections returns true if organisms can face and move diagonally
+func (g *Geometry) HasEightDirections() bool { return g.eightDirections }
+
+// Directions returns every direction organisms can face on the grid, in
+// clockwise order
+func (g *Geometry) Directions() []Point {
+	if g.eightDirections {
+		return EightDirections[:]
+	}
+	return Directions[:]
+}
+
+// RandomDirection returns a random direction organisms can face on the grid
+func (g *Geometry) RandomDirection(r *rand.Rand) Point {
+	directions := g.Directions()
+	return directions[r.Intn(len(directions))]
+}
+
+// CutsCorner returns true if a diagonal step from a point squeezes between
+// the corners of walls, which would let organisms slip through diagonal lines
+// of walls. Steps in the four cardinal directions never cut corners.
+func (g *Geometry) CutsCorner(p, direction Point) bool {
+	if !direction.IsDiagonal() {
+		return false
+	}
+	return g.IsWall(g.Add(p, Point{X: direction.X})) || g.IsWall(g.Add(p, Point{Y: direction.Y}))
+}
+
+// InBounds returns true if a point lies within the grid
+func (g *Geometry) InBounds(p Point) bool {
+	return p.InBounds(g.width, g.height)
+}
+
 // Wrap returns a point value after wrapping it around the grid
-func (p Point) Wrap() Point {
+func (g *Geometry) Wrap(p Point) Point {
 	return Point{
-		X: (p.X + c.GridUnitsWide()) % c.GridUnitsWide(),
-		Y: (p.Y + c.GridUnitsHigh()) % c.GridUnitsHigh(),
+		X: (p.X + g.width) % g.width,
+		Y: (p.Y + g.height) % g.height,
 	}
 }
 
-// IsWall returns true if the given point is on a pool border
-func (p *Point) IsWall() bool {
-	return IsWall(p.X, p.Y)
+// HasWalls returns true if the grid has any walls inside of it
+func (g *Geometry) HasWalls() bool {
+	return g.usePools || g.wallMap != nil
+}
+
+// IsWall returns true if the given point is on a wall
+func (g *Geometry) IsWall(p Point) bool {
+	return g.IsWallAt(p.X, p.Y)
 }
 
-// IsWall returns true if some given coordinates are on a pool border, making
-// sure to allow movement through 'gates' in the center of each wall.
-func IsWall(x, y int) bool {
-	if c.UsePools() == false {
+// IsWallAt returns true if some given coordinates are on a wall from the wall
+// map or a pool border, making sure to allow movement through 'gates' in the
+// center of each pool border. Coordinates outside of a bounded grid are
+// always walls.
+func (g *Geometry) IsWallAt(x, y int) bool {
+	if x < 0 || y < 0 || x >= g.width || y >= g.height {
+		return true
+	}
+	if g.wallMap != nil && g.wallMap.IsWallAt(x, y) {
+		return true
+	}
+	if g.usePools == false {
 		return false
 	}
 
-	if x%c.PoolWidth() == 0 && (y+(c.PoolHeight()/2))%c.PoolHeight() != 0 {
+	if x%g.poolWidth == 0 && (y+(g.poolHeight/2))%g.poolHeight != 0 {
 		return true
 	}
-	if y%c.PoolHeight() == 0 && (x+(c.PoolWidth()/2))%c.PoolWidth() != 0 {
+	if y%g.poolHeight == 0 && (x+(g.poolWidth/2))%g.poolWidth != 0 {
 		return true
 	}
 	return false
@@ -92,40 +208,41 @@ func (p *Point) ToString() string {
 	return fmt.Sprintf("%d,%d", p.X, p.Y)
 }
 
-// Right returns the direction to the right of the current direction d
-func (p Point) Right() (right Point) {
-	switch p {
-	case directionUp:
-		right = directionRight
-		break
-	case directionRight:
-		right = directionDown
-		break
-	case directionDown:
-		right = directionLeft
-		break
-	case directionLeft:
-		right = directionUp
-		break
-	}
-	return
-}
-
-// Left returns the direction to the right of the current direction d
-func (p Point) Left() (left Point) {
-	switch p {
-	case directionUp:
-		left = directionLeft
-		break
-	case directionRight:
-		left = directionUp
-		break
-	case directionDown:
-		left = directionRight
-		break
-	case directionLeft:
-		left = directionDown
-		break
-	}
-	return
+// IsDiagonal returns true if a direction points between two of the cardinal
+// directions
+func (p Point) IsDiagonal() bool {
+	return p.X != 0 && p.Y != 0
+}
+
+// Right returns the direction 90 degrees to the right of the current direction
+func (p Point) Right() Point {
+	return Point{X: -p.Y, Y: p.X}
+}
+
+// Left returns the direction 90 degrees to the left of the current direction
+func (p Point) Left() Point {
+	return Point{X: p.Y, Y: -p.X}
+}
+
+// HalfRight returns the direction 45 degrees to the right of the current
+// direction, which is diagonal if the current direction isn't
+func (p Point) HalfRight() Point {
+	return Point{X: sign(p.X - p.Y), Y: sign(p.X + p.Y)}
+}
+
+// HalfLeft returns the direction 45 degrees to the left of the current
+// direction, which is diagonal if the current direction isn't
+func (p Point) HalfLeft() Point {
+	return Point{X: sign(p.X + p.Y), Y: sign(p.Y - p.X)}
+}
+
+// sign returns -1, 0 or 1 for negative, zero or positive values
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
 }
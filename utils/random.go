@@ -0,0 +1,63 @@
+package utils
+
+import "math/rand"
+
+// Random is a stream of pseudo-random numbers whose full state can be saved
+// and restored, unlike the sources provided by math/rand. Each Random should
+// only be used by one goroutine at a time.
+type Random struct {
+	*rand.Rand
+	source *source
+}
+
+// NewRandom returns a Random stream initialized with the given seed
+func NewRandom(seed int64) *Random {
+	s := &source{}
+	s.Seed(seed)
+	return &Random{
+		Rand:   rand.New(s),
+		source: s,
+	}
+}
+
+// RandomFromState returns a Random stream that continues from a state
+// previously returned by State
+func RandomFromState(state uint64) *Random {
+	s := &source{state: state}
+	return &Random{
+		Rand:   rand.New(s),
+		source: s,
+	}
+}
+
+// State returns the current state of the stream
+func (r *Random) State() uint64 {
+	return r.source.state
+}
+
+// NewStream returns a new, independent Random stream seeded from this one
+func (r *Random) NewStream() *Random {
+	return NewRandom(r.Int63())
+}
+
+// source implements rand.Source64 with the splitmix64 algorithm, which keeps
+// all of its state in a single integer
+type source struct {
+	state uint64
+}
+
+func (s *source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/Zebbeni/protozoa/config"
 	"github.com/Zebbeni/protozoa/decision"
+	"github.com/Zebbeni/protozoa/environment"
 	"github.com/Zebbeni/protozoa/food"
 	"github.com/Zebbeni/protozoa/organism"
 	"github.com/Zebbeni/protozoa/resources"
@@ -14,6 +15,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/lucasb-eyer/go-colorful"
 	"math"
+	"strings"
 )
 
 type size int
@@ -33,6 +35,9 @@ const (
 	organismsOnlyMode
 	phEffectsOnlyMode
 	phOnlyMode
+	// fieldOnlyMode shows one of the config's fields other than pH, so it is
+	// switched to after the modes in viewModes once for each field
+	fieldOnlyMode
 )
 
 const (
@@ -51,6 +56,8 @@ var (
 
 	foodColor          = colorful.HSLuv(120, 0.2, 0.25)
 	wallColor          = colorful.HSLuv(60, 0.25, 0.1)
+	acidicVentColor    = colorful.HSLuv(phMaxHue, 1.0, 0.8)
+	alkalineVentColor  = colorful.HSLuv(0, 1.0, 0.8)
 	attackColor        = colorful.HSLuv(0.0, 255.0, 1.0)
 	selectColor        = colorful.HSLuv(0.0, 255.0, 1.0)
 	hoverColor         = colorful.HSLuv(0.0, 0, 0.7)
@@ -73,30 +80,41 @@ var (
 
 type Grid struct {
 	simulation *simulation.Simulation
+	cfg        *config.Globals
+	unitSize   int
 
 	previousEnvImage   *ebiten.Image
 	previousWallsImage *ebiten.Image
 	previousFoodImage  *ebiten.Image
 	previousOrgsImage  *ebiten.Image
+	previousFieldImage *ebiten.Image
+
+	// fieldCycle is the cycle the field shown in fieldOnlyMode was last drawn
+	// on, since every value changes each cycle
+	fieldCycle int
 
 	mouseHoverLocation utils.Point
 	mouseOnGrid        bool
 	doRefresh          bool
 	viewMode           mode
+	viewField          int
 	selectMode         mode
 }
 
 func NewGrid(simulation *simulation.Simulation) *Grid {
 	g := &Grid{
-		simulation:         simulation,
-		previousWallsImage: newBlankLayer(),
-		previousEnvImage:   newBlankLayer(),
-		previousFoodImage:  newBlankLayer(),
-		previousOrgsImage:  newBlankLayer(),
-		doRefresh:          true,
-		viewMode:           orgsPhMode,
-		selectMode:         selectOldest,
+		simulation: simulation,
+		cfg:        simulation.Config(),
+		unitSize:   simulation.Config().UnitSize(),
+		doRefresh:  true,
+		viewMode:   orgsPhMode,
+		selectMode: selectOldest,
 	}
+	g.previousWallsImage = g.newBlankLayer()
+	g.previousEnvImage = g.newBlankLayer()
+	g.previousFoodImage = g.newBlankLayer()
+	g.previousOrgsImage = g.newBlankLayer()
+	g.previousFieldImage = g.newBlankLayer()
 	loadOrganismImages()
 	return g
 }
@@ -111,12 +129,12 @@ func loadOrganismImages() {
 
 // Render draws all organisms and food on the simulation grid
 func (g *Grid) Render() *ebiten.Image {
-	envImage := newBlankLayer()
-	wallsImage := newBlankLayer()
-	foodImage := newBlankLayer()
-	orgsImage := newBlankLayer()
-	selImage := newBlankLayer()
-	gridImage := newBlankLayer()
+	envImage := g.newBlankLayer()
+	wallsImage := g.newBlankLayer()
+	foodImage := g.newBlankLayer()
+	orgsImage := g.newBlankLayer()
+	selImage := g.newBlankLayer()
+	gridImage := g.newBlankLayer()
 
 	g.renderWalls(wallsImage, g.doRefresh)
 	g.renderEnvironment(envImage, g.doRefresh)
@@ -133,9 +151,16 @@ func (g *Grid) Render() *ebiten.Image {
 		gridImage.DrawImage(envImage, nil)
 	}
 
+	if g.viewMode == fieldOnlyMode {
+		fieldImage := g.newBlankLayer()
+		g.renderField(fieldImage, g.doRefresh)
+		g.previousFieldImage = fieldImage
+		gridImage.DrawImage(fieldImage, nil)
+	}
+
 	gridImage.DrawImage(wallsImage, nil)
 
-	if g.viewMode != phOnlyMode {
+	if g.viewMode != phOnlyMode && g.viewMode != fieldOnlyMode {
 		gridImage.DrawImage(foodImage, nil)
 		gridImage.DrawImage(orgsImage, nil)
 	}
@@ -166,22 +191,48 @@ func (g *Grid) renderEnvironment(envImage *ebiten.Image, refresh bool) {
 	}
 }
 
+// renderField draws the values of the field being viewed, redrawing all of
+// them once per cycle
+func (g *Grid) renderField(fieldImage *ebiten.Image, refresh bool) {
+	cycle := g.simulation.Cycle()
+	if !refresh && cycle == g.fieldCycle {
+		fieldImage.DrawImage(g.previousFieldImage, nil)
+		return
+	}
+	g.fieldCycle = cycle
+	field := g.cfg.Fields[g.viewField]
+	fieldMap := g.simulation.GetFieldMap(g.viewField)
+	for x := range fieldMap {
+		for y := range fieldMap[x] {
+			level := (fieldMap[x][y] - field.Min) / (field.Max - field.Min)
+			if field.Max == field.Min {
+				level = 0.5
+			}
+			col := colorful.HSLuv(field.Hue, 0.9, 0.05+0.75*level)
+			g.drawSquare(fieldImage, float64(x*g.unitSize), float64(y*g.unitSize), sizeFill, col)
+		}
+	}
+}
+
 func (g *Grid) renderWalls(wallsImage *ebiten.Image, refresh bool) {
 	if refresh {
 		wallPoints := g.simulation.GetWalls()
 		for _, wallPoint := range wallPoints {
 			g.renderWall(wallsImage, wallPoint)
 		}
+		for _, vent := range g.simulation.GetVents() {
+			g.renderVent(wallsImage, vent)
+		}
 	} else {
 		wallsImage.DrawImage(g.previousWallsImage, nil)
 	}
 }
 
 func (g *Grid) renderPhValue(envImage *ebiten.Image, gridX, gridY int, phVal float64) {
-	x := float64(gridX) * float64(config.GridUnitSize())
-	y := float64(gridY) * float64(config.GridUnitSize())
-	hue := phMaxHue - (phMaxHue * phVal / config.MaxPh())
-	sat := math.Abs(phVal-((config.MaxPh()+config.MinPh())/2.0)) / (config.MaxPh() - config.MinPh())
+	x := float64(gridX) * float64(g.unitSize)
+	y := float64(gridY) * float64(g.unitSize)
+	hue := phMaxHue - (phMaxHue * phVal / g.cfg.MaxPh)
+	sat := math.Abs(phVal-((g.cfg.MaxPh+g.cfg.MinPh)/2.0)) / (g.cfg.MaxPh - g.cfg.MinPh)
 	light := 0.5 + (0.5 * math.Sin(math.Pi*(sat-0.5)))
 	col := colorful.HSLuv(hue, sat, light)
 	g.drawSquare(envImage, x, y, sizeFill, col)
@@ -198,7 +249,7 @@ func (g *Grid) renderFood(foodImage *ebiten.Image, refresh bool) {
 		updatedPoints := g.simulation.GetUpdatedFoodPoints()
 		for _, point := range updatedPoints {
 			// clear square to be updated
-			x, y := point.X*config.GridUnitSize(), point.Y*config.GridUnitSize()
+			x, y := point.X*g.unitSize, point.Y*g.unitSize
 			g.clearSquare(foodImage, float64(x), float64(y))
 
 			if item, exists := g.simulation.GetFoodAtPoint(point); exists {
@@ -219,7 +270,7 @@ func (g *Grid) renderOrganisms(organismsImage *ebiten.Image, refresh bool) {
 		updatedPoints := g.simulation.GetUpdatedOrganismPoints()
 		for _, point := range updatedPoints {
 			// clear square to be updated
-			x, y := point.X*config.GridUnitSize(), point.Y*config.GridUnitSize()
+			x, y := point.X*g.unitSize, point.Y*g.unitSize
 			g.clearSquare(organismsImage, float64(x), float64(y))
 
 			if info := g.simulation.GetOrganismInfoAtPoint(point); info != nil {
@@ -233,6 +284,12 @@ func (g *Grid) renderSelections(selectionsImage *ebiten.Image) {
 	if g.mouseOnGrid {
 		infoColor := hoverColor
 		infoText := fmt.Sprintf("PH: %2.1f", g.simulation.GetPhAtPoint(g.mouseHoverLocation))
+		if g.cfg.DayLength > 0 || g.cfg.MinLight != g.cfg.MaxLight {
+			infoText += fmt.Sprintf("\nLIGHT: %.2f", g.simulation.GetLightAtPoint(g.mouseHoverLocation))
+		}
+		for i, field := range g.cfg.Fields {
+			infoText += fmt.Sprintf("\n%s: %.1f", strings.ToUpper(field.Name), g.simulation.GetFieldAtPoint(i, g.mouseHoverLocation))
+		}
 		if info := g.simulation.GetOrganismInfoAtPoint(g.mouseHoverLocation); info != nil {
 			infoText += fmt.Sprintf("\nORG: %d", info.ID)
 			infoText += fmt.Sprintf("\nSIZE: %.0f", info.Size)
@@ -242,6 +299,11 @@ func (g *Grid) renderSelections(selectionsImage *ebiten.Image) {
 				infoText += fmt.Sprintf("\nFOOD: %d", foodItem.Value)
 			}
 		}
+		for _, vent := range g.simulation.GetVents() {
+			if vent.Point == g.mouseHoverLocation {
+				infoText += fmt.Sprintf("\nVENT: %+.2f %s", vent.Strength, g.ventFieldName(vent))
+			}
+		}
 		infoText += fmt.Sprintf("\nPOINT: %v", g.mouseHoverLocation)
 
 		g.renderSelection(g.mouseHoverLocation, selectionsImage, infoColor)
@@ -254,14 +316,40 @@ func (g *Grid) renderSelections(selectionsImage *ebiten.Image) {
 	}
 }
 
-func newBlankLayer() *ebiten.Image {
-	return ebiten.NewImage(config.GridWidth(), config.GridHeight())
+func (g *Grid) newBlankLayer() *ebiten.Image {
+	return ebiten.NewImage(g.cfg.GridWidth(), g.cfg.GridHeight())
 }
 
-// ChangeViewMode switches to the next mode listed in viewModes
+// ChangeViewMode switches to the next mode listed in viewModes, followed by
+// fieldOnlyMode for each of the config's fields
 func (g *Grid) ChangeViewMode() {
-	g.viewMode = viewModes[(int(g.viewMode)+1)%len(viewModes)]
 	g.doRefresh = true
+	switch {
+	case g.viewMode == fieldOnlyMode && g.viewField+1 < len(g.cfg.Fields):
+		g.viewField++
+	case g.viewMode == fieldOnlyMode:
+		g.viewMode = viewModes[0]
+	case g.viewMode == viewModes[len(viewModes)-1] && len(g.cfg.Fields) > 0:
+		g.viewMode, g.viewField = fieldOnlyMode, 0
+	default:
+		g.viewMode = viewModes[(int(g.viewMode)+1)%len(viewModes)]
+	}
+}
+
+// viewModeName returns the name of the current view mode
+func (g *Grid) viewModeName() string {
+	if g.viewMode == fieldOnlyMode {
+		return strings.ToUpper(g.cfg.Fields[g.viewField].Name) + " ONLY"
+	}
+	return viewModeNames[g.viewMode]
+}
+
+// ventFieldName returns the name of the field a vent changes
+func (g *Grid) ventFieldName(vent environment.Vent) string {
+	if vent.Field == "" {
+		return "PH"
+	}
+	return strings.ToUpper(vent.Field)
 }
 
 // UpdateAutoSelect switches to the next auto select mode listed in selectModes
@@ -283,20 +371,20 @@ func (g *Grid) MouseHover(point utils.Point, onGrid bool) {
 
 // renderSelection draws a square around a single item on the grid
 func (g *Grid) renderSelection(point utils.Point, img *ebiten.Image, col colorful.Color) {
-	x, y := float64(point.X*config.GridUnitSize()), float64(point.Y*config.GridUnitSize())
-	ebitenutil.DrawLine(img, x-2, y-2, x+float64(config.GridUnitSize())+3, y-2, col)                                                               // top
-	ebitenutil.DrawLine(img, x-2, y-2, x-2, y+float64(config.GridUnitSize())+3, col)                                                               // left
-	ebitenutil.DrawLine(img, x-2, y+float64(config.GridUnitSize())+3, x+float64(config.GridUnitSize())+3, y+float64(config.GridUnitSize())+3, col) // bottom
-	ebitenutil.DrawLine(img, x+float64(config.GridUnitSize())+3, y-2, x+float64(config.GridUnitSize())+3, y+float64(config.GridUnitSize())+3, col) // right
+	x, y := float64(point.X*g.unitSize), float64(point.Y*g.unitSize)
+	ebitenutil.DrawLine(img, x-2, y-2, x+float64(g.unitSize)+3, y-2, col)                                         // top
+	ebitenutil.DrawLine(img, x-2, y-2, x-2, y+float64(g.unitSize)+3, col)                                         // left
+	ebitenutil.DrawLine(img, x-2, y+float64(g.unitSize)+3, x+float64(g.unitSize)+3, y+float64(g.unitSize)+3, col) // bottom
+	ebitenutil.DrawLine(img, x+float64(g.unitSize)+3, y-2, x+float64(g.unitSize)+3, y+float64(g.unitSize)+3, col) // right
 }
 
 func (g *Grid) renderSelectionText(point utils.Point, img *ebiten.Image, message string, col colorful.Color) {
 	xPadding := 10
 	bounds := text.BoundString(resources.FontSourceCodePro10, message)
-	x := xPadding + config.GridUnitSize() + (point.X * config.GridUnitSize())
-	y := point.Y * config.GridUnitSize()
-	if x+bounds.Dx() > config.GridWidth() {
-		x = (point.X * config.GridUnitSize()) - xPadding - bounds.Dx()
+	x := xPadding + g.unitSize + (point.X * g.unitSize)
+	y := point.Y * g.unitSize
+	if x+bounds.Dx() > g.cfg.GridWidth() {
+		x = (point.X * g.unitSize) - xPadding - bounds.Dx()
 	}
 	text.Draw(img, message, resources.FontSourceCodePro10, x, y, col)
 }
@@ -306,20 +394,20 @@ func (g *Grid) renderViewModeName(img *ebiten.Image) {
 	yPadding := 20
 	x := xPadding
 	y := yPadding
-	info := fmt.Sprintf("VIEW MODE: %s\nSELECTED: %s", viewModeNames[g.viewMode], selectModeNames[g.selectMode])
+	info := fmt.Sprintf("VIEW MODE: %s\nSELECTED: %s", g.viewModeName(), selectModeNames[g.selectMode])
 	text.Draw(img, info, resources.FontSourceCodePro10, x, y, selectionInfoColor)
 }
 
 // renderFoodItem draws a food item to the given image
 func (g *Grid) renderFoodItem(item *food.Item, img *ebiten.Image) {
-	x := float64(item.Point.X) * float64(config.GridUnitSize())
-	y := float64(item.Point.Y) * float64(config.GridUnitSize())
+	x := float64(item.Point.X) * float64(g.unitSize)
+	y := float64(item.Point.Y) * float64(g.unitSize)
 
 	value := float64(item.Value)
 	foodSize := sizeSmall
-	if value < float64(config.MaxFoodValue())*0.4375 {
+	if value < float64(g.cfg.MaxFoodValue)*0.4375 {
 		foodSize = sizeSmall
-	} else if value < float64(config.MaxFoodValue())*0.8125 {
+	} else if value < float64(g.cfg.MaxFoodValue)*0.8125 {
 		foodSize = sizeMedium
 	} else {
 		foodSize = sizeLarge
@@ -330,21 +418,41 @@ func (g *Grid) renderFoodItem(item *food.Item, img *ebiten.Image) {
 
 // renderWall draws a wall icon to the given image
 func (g *Grid) renderWall(wallsImage *ebiten.Image, point utils.Point) {
-	x := float64(point.X) * float64(config.GridUnitSize())
-	y := float64(point.Y) * float64(config.GridUnitSize())
+	x := float64(point.X) * float64(g.unitSize)
+	y := float64(point.Y) * float64(g.unitSize)
 
 	g.drawSquare(wallsImage, x, y, sizeBox, wallColor)
 }
 
+// renderVent draws a vent marker to the given image, colored like the pH it
+// pushes its surroundings toward, or in the color of the field it changes,
+// light for sources and dark for sinks
+func (g *Grid) renderVent(wallsImage *ebiten.Image, vent environment.Vent) {
+	x := float64(vent.Point.X) * float64(g.unitSize)
+	y := float64(vent.Point.Y) * float64(g.unitSize)
+
+	ventColor := acidicVentColor
+	if i, ok := g.cfg.FieldIndex(vent.Field); ok && vent.Field != "" {
+		ventColor = colorful.HSLuv(g.cfg.Fields[i].Hue, 1.0, 0.35)
+		if vent.IsSource() {
+			ventColor = colorful.HSLuv(g.cfg.Fields[i].Hue, 1.0, 0.9)
+		}
+	} else if vent.IsSource() {
+		ventColor = alkalineVentColor
+	}
+	g.drawSquare(wallsImage, x, y, sizeBox, ventColor)
+	g.drawSquare(wallsImage, x, y, sizeSmall, ventColor)
+}
+
 // renderOrganism draws an organism to the given image
 func (g *Grid) renderOrganism(info *organism.Info, img *ebiten.Image) {
-	point := info.Location.Times(config.GridUnitSize())
+	point := info.Location.Times(g.unitSize)
 	x, y := float64(point.X), float64(point.Y)
 
 	organismSize := sizeSmall
-	if info.Size < config.MaximumMaxSize()*0.4375 {
+	if info.Size < g.cfg.MaximumMaxSize*0.4375 {
 		organismSize = sizeSmall
-	} else if info.Size < config.MaximumMaxSize()*0.8125 {
+	} else if info.Size < g.cfg.MaximumMaxSize*0.8125 {
 		organismSize = sizeMedium
 	} else {
 		organismSize = sizeLarge
@@ -353,7 +461,7 @@ func (g *Grid) renderOrganism(info *organism.Info, img *ebiten.Image) {
 	organismColor := info.Color
 
 	if g.viewMode == phEffectsOnlyMode {
-		maxEffect := config.MaxOrganismPhGrowthEffect() * info.Size
+		maxEffect := g.cfg.MaxOrganismPhGrowthEffect * info.Size
 		spectrumValue := (info.Size*info.PhEffect + maxEffect) / (2 * maxEffect)
 		hue := phMaxHue - (phMaxHue * spectrumValue)
 		sat := 1.0 + math.Abs(spectrumValue-0.5)
@@ -1,17 +1,28 @@
 package ux
 
 import (
+	"log"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
-	"github.com/Zebbeni/protozoa/config"
 	"github.com/Zebbeni/protozoa/organism"
 	"github.com/Zebbeni/protozoa/simulation"
 	"github.com/Zebbeni/protozoa/utils"
 )
 
+// multiStepCycles is the number of cycles stepped through at once with [Shift+N]
+const multiStepCycles = 10
+
+// fastestSpeed is the speed at which as many cycles are run as possible
+// between frames
+const fastestSpeed = 0
+
+// speeds lists the number of cycles run per frame that can be chosen with
+// [+] and [-]
+var speeds = []int{1, 2, 4, 8, 16, 32, 64, fastestSpeed}
+
 type Interface struct {
 	simulation *simulation.Simulation
 	selection  *organism.Info
@@ -20,6 +31,18 @@ type Interface struct {
 	panel *Panel
 	debug *Debug
 
+	// replay is only set while playing back a recording
+	replay *ReplayControls
+
+	tuning *TuningControls
+
+	// requestedSteps is the number of cycles the user has asked to step
+	// through while paused
+	requestedSteps int
+
+	// speedIndex is the index of the current speed in speeds
+	speedIndex int
+
 	gridOptions  *ebiten.DrawImageOptions
 	panelOptions *ebiten.DrawImageOptions
 	debugOptions *ebiten.DrawImageOptions
@@ -34,6 +57,7 @@ func NewInterface(sim *simulation.Simulation) *Interface {
 		panelOptions: &ebiten.DrawImageOptions{},
 	}
 	i.gridOptions.GeoM.Translate(panelWidth, 0)
+	i.setTuning(NewTuningControls(sim, false))
 
 	i.debug = NewDebug(sim)
 	i.debugOptions = &ebiten.DrawImageOptions{}
@@ -56,9 +80,53 @@ func (i *Interface) Render(screen *ebiten.Image) {
 	}
 }
 
-func (i *Interface) HandleUserInput() {
+func (i *Interface) HandleUserInput() error {
 	i.handleKeyboard()
 	i.handleMouse()
+	if i.replay != nil {
+		return i.replay.handleKeyboard()
+	}
+	return nil
+}
+
+func (i *Interface) setTuning(tuning *TuningControls) {
+	i.tuning = tuning
+	i.panel.tuning = tuning
+}
+
+func (i *Interface) changeSpeed(change int) {
+	i.speedIndex += change
+	if i.speedIndex < 0 {
+		i.speedIndex = 0
+	} else if i.speedIndex >= len(speeds) {
+		i.speedIndex = len(speeds) - 1
+	}
+	i.panel.speed = speeds[i.speedIndex]
+}
+
+// CyclesPerFrame returns the number of cycles to run between frames, or 0 if
+// as many cycles should be run as possible
+func (i *Interface) CyclesPerFrame() int {
+	return speeds[i.speedIndex]
+}
+
+// TakeRequestedSteps returns the number of cycles the user has asked to step
+// through since the last call
+func (i *Interface) TakeRequestedSteps() int {
+	steps := i.requestedSteps
+	i.requestedSteps = 0
+	return steps
+}
+
+// IsReplay returns true if the interface is playing back a recording
+func (i *Interface) IsReplay() bool {
+	return i.replay != nil
+}
+
+// UpdateReplay plays back any recorded cycles stepped through while paused,
+// or as many as the replay speed allows while playing
+func (i *Interface) UpdateReplay() error {
+	return i.replay.update(i.TakeRequestedSteps())
 }
 
 func (i *Interface) handleKeyboard() {
@@ -71,9 +139,39 @@ func (i *Interface) handleKeyboard() {
 	if inpututil.IsKeyJustReleased(ebiten.KeyO) {
 		i.grid.UpdateAutoSelect()
 	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyN) && i.simulation.IsPaused() {
+		i.requestedSteps++
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			i.requestedSteps += multiStepCycles - 1
+		}
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyEqual) || inpututil.IsKeyJustReleased(ebiten.KeyKPAdd) {
+		i.changeSpeed(1)
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyMinus) || inpututil.IsKeyJustReleased(ebiten.KeyKPSubtract) {
+		i.changeSpeed(-1)
+	}
 	if inpututil.IsKeyJustReleased(ebiten.KeyD) {
 		i.simulation.ToggleDebug()
 	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyS) && i.replay == nil {
+		i.saveSnapshot()
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyT) {
+		i.panel.showTuning = !i.panel.showTuning
+	}
+	if i.panel.showTuning {
+		i.tuning.handleKeyboard()
+	}
+}
+
+func (i *Interface) saveSnapshot() {
+	path := i.simulation.SnapshotFile()
+	if err := i.simulation.SaveSnapshot(path); err != nil {
+		log.Printf("failed to save snapshot: %v", err)
+		return
+	}
+	log.Printf("saved cycle %d to %s", i.simulation.Cycle(), path)
 }
 
 func (i *Interface) UpdateSelected() {
@@ -96,6 +194,9 @@ func (i *Interface) UpdateSelected() {
 // events, I think this is fine.
 func (i *Interface) handleMouse() {
 	i.handleMouseHover()
+	if i.panel.showTuning {
+		i.tuning.handleMouse(ebiten.CursorPosition())
+	}
 
 	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
 		i.handleLeftClick()
@@ -138,10 +239,11 @@ func (i *Interface) getMouseGridLocation() (utils.Point, bool) {
 	mouseX, mouseY := ebiten.CursorPosition()
 	relativeGridX := mouseX - panelWidth
 	relativeGridY := mouseY
-	gridX := relativeGridX / config.GridUnitSize()
-	gridY := relativeGridY / config.GridUnitSize()
-	gridW := config.GridUnitsWide()
-	gridH := config.GridUnitsHigh()
+	cfg := i.simulation.Config()
+	gridX := relativeGridX / cfg.UnitSize()
+	gridY := relativeGridY / cfg.UnitSize()
+	gridW := cfg.GridUnitsWide
+	gridH := cfg.GridUnitsHigh
 	onGrid := gridX >= 0 && gridY >= 0 && gridX < gridW && gridY < gridH
 	return utils.Point{X: gridX, Y: gridY}, onGrid
 }
@@ -3,19 +3,20 @@ package ux
 import (
 	"fmt"
 	"image/color"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 
+	c "github.com/Zebbeni/protozoa/config"
 	r "github.com/Zebbeni/protozoa/resources"
 	s "github.com/Zebbeni/protozoa/simulation"
 )
 
 const (
-	padding     = 15
-	panelWidth  = 400
-	panelHeight = 1000
+	padding    = 15
+	panelWidth = c.PanelWidth
 
 	titleXOffset = padding
 	titleYOffset = padding
@@ -30,7 +31,7 @@ const (
 
 	graphXOffset = padding
 	graphYOffset = 130
-	graphWidth   = 370
+	graphWidth   = panelWidth - 2*padding
 	graphHeight  = 120
 )
 
@@ -38,17 +39,39 @@ type Panel struct {
 	simulation         *s.Simulation
 	previousPanelImage *ebiten.Image
 	graph              *Graph
+
+	// height is the height of the window the panel fills
+	height int
+
+	// replay is only set while playing back a recording
+	replay *ReplayControls
+
+	// tuning shows settings that can be changed, in place of the selected
+	// organism while showTuning is true
+	tuning     *TuningControls
+	showTuning bool
+
+	// speed is the number of cycles run per frame, or fastestSpeed
+	speed int
 }
 
 func NewPanel(sim *s.Simulation) *Panel {
 	return &Panel{
 		simulation: sim,
 		graph:      NewGraph(sim),
+		height:     panelHeight(sim.Config()),
+		speed:      speeds[0],
 	}
 }
 
+// panelHeight returns the height of the window the panel is drawn in
+func panelHeight(cfg *c.Globals) int {
+	_, height := cfg.WindowSize()
+	return height
+}
+
 func (p *Panel) Render() *ebiten.Image {
-	panelImage := ebiten.NewImage(panelWidth, panelHeight)
+	panelImage := ebiten.NewImage(panelWidth, p.height)
 
 	if p.shouldRefresh() {
 		p.renderDividingLine(panelImage)
@@ -56,9 +79,13 @@ func (p *Panel) Render() *ebiten.Image {
 		p.renderKeyBindingText(panelImage)
 		p.renderStats(panelImage)
 		p.renderGraph(panelImage)
-		p.renderSelected(panelImage)
+		if p.showTuning {
+			p.tuning.render(panelImage)
+		} else {
+			p.renderSelected(panelImage)
+		}
 
-		p.previousPanelImage = ebiten.NewImage(panelWidth, panelHeight)
+		p.previousPanelImage = ebiten.NewImage(panelWidth, p.height)
 		p.previousPanelImage.DrawImage(panelImage, nil)
 	} else {
 		panelImage.DrawImage(p.previousPanelImage, nil)
@@ -72,7 +99,7 @@ func (p *Panel) shouldRefresh() bool {
 }
 
 func (p *Panel) renderDividingLine(panelImage *ebiten.Image) {
-	ebitenutil.DrawRect(panelImage, float64(panelWidth)-1, 0, float64(panelWidth), float64(panelHeight), color.White)
+	ebitenutil.DrawRect(panelImage, float64(panelWidth)-1, 0, float64(panelWidth), float64(p.height), color.White)
 }
 
 func (p *Panel) renderTitle(panelImage *ebiten.Image) {
@@ -81,9 +108,20 @@ func (p *Panel) renderTitle(panelImage *ebiten.Image) {
 }
 
 func (p *Panel) renderKeyBindingText(panelImage *ebiten.Image) {
-	message := "[Space] to Pause\n[M] to Change Mode\n[O] to Auto Select"
+	message := "[Space] to Pause\n[+/-] to Change Speed\n[M] to Change Mode\n[O] to Auto Select\n[S] to Save"
 	if p.simulation.IsPaused() {
-		message = "[Space] to Resume\n[M] to Change Mode"
+		message = "[Space] to Resume\n[N] to Step\n[Shift+N] to Step 10\n[M] to Change Mode\n[S] to Save"
+	}
+	if p.replay != nil {
+		message = "[Space] to Pause\n[Left/Right] to Seek\n[Up/Down] to Change Speed\n[M] to Change Mode"
+		if p.simulation.IsPaused() {
+			message = "[Space] to Play\n[N] to Step\n[Shift+N] to Step 10\n[Left/Right] to Seek\n[Up/Down] to Change Speed\n[M] to Change Mode"
+		}
+	}
+	if p.showTuning {
+		message += "\n[T] to Hide Settings"
+	} else {
+		message += "\n[T] to Show Settings"
 	}
 
 	bounds := text.BoundString(r.FontSourceCodePro10, message)
@@ -94,11 +132,21 @@ func (p *Panel) renderKeyBindingText(panelImage *ebiten.Image) {
 func (p *Panel) renderStats(panelImage *ebiten.Image) {
 	statsString := fmt.Sprintf("CYCLE: %9d\nORGANISMS: %5d\nDEAD: %10d",
 		p.simulation.Cycle(), p.simulation.OrganismCount(), p.simulation.GetDeadCount())
+	if p.replay != nil {
+		statsString += "\n" + p.replay.status()
+	} else {
+		speed := fmt.Sprintf("%dx", p.speed)
+		if p.speed == fastestSpeed {
+			speed = "MAX"
+		}
+		statsString += fmt.Sprintf("\nSPEED: %9s", speed)
+	}
 	text.Draw(panelImage, statsString, r.FontSourceCodePro12, statsXOffset, statsYOffset, color.White)
 }
 
 func (p *Panel) renderGraph(panelImage *ebiten.Image) {
 	text.Draw(panelImage, "HISTORY", r.FontSourceCodePro12, graphXOffset, graphYOffset, color.White)
+	p.renderRegimes(panelImage)
 	graphImage := p.graph.Render()
 	graphOptions := &ebiten.DrawImageOptions{}
 	scaleX := float64(graphWidth) / float64(graphImage.Bounds().Dx())
@@ -116,6 +164,21 @@ func (p *Panel) renderGraph(panelImage *ebiten.Image) {
 	ebitenutil.DrawLine(panelImage, left, top, left, bottom, color.White)
 }
 
+// renderRegimes shows the timeline regimes active on the current cycle, if the
+// simulation has a timeline
+func (p *Panel) renderRegimes(panelImage *ebiten.Image) {
+	if len(p.simulation.Config().Timeline) == 0 {
+		return
+	}
+	regimes := "NONE"
+	if active := p.simulation.ActiveRegimes(); len(active) > 0 {
+		regimes = strings.ToUpper(strings.Join(active, ", "))
+	}
+	message := "REGIME: " + regimes
+	bounds := text.BoundString(r.FontSourceCodePro10, message)
+	text.Draw(panelImage, message, r.FontSourceCodePro10, graphXOffset+graphWidth-bounds.Dx(), graphYOffset, color.White)
+}
+
 func (p *Panel) renderSelected(panelImage *ebiten.Image) {
 	id := p.simulation.GetSelected()
 	info := p.simulation.GetOrganismInfoByID(id)
@@ -131,6 +194,10 @@ func (p *Panel) renderSelected(panelImage *ebiten.Image) {
 	infoString += fmt.Sprintf("\nAGE:            %7d       CHILDREN:   %7d", info.Age, info.Children)
 	infoString += fmt.Sprintf("\nMUTATE CHANCE:     %3.0f%%       SPAWN HEALTH: %[4]*.[3]*[2]f", traits.ChanceToMutateDecisionTree*100.0, traits.MinHealthToSpawn, 2, 5)
 	infoString += fmt.Sprintf("\nPH TOLERANCE:   %1.1f-%1.1f       PH EFFECT: %+1.5f", traits.IdealPh-traits.PhTolerance, traits.IdealPh+traits.PhTolerance, traits.PhGrowthEffect)
+	for i, field := range p.simulation.Config().Fields {
+		trait := traits.FieldTraits[i]
+		infoString += fmt.Sprintf("\n%-15s %1.1f-%1.1f", strings.ToUpper(field.Name)+" TOLERANCE:", trait.Ideal-trait.Tolerance, trait.Ideal+trait.Tolerance)
+	}
 	bounds := text.BoundString(r.FontSourceCodePro12, infoString)
 	offsetY := selectedYOffset + bounds.Dy() + padding
 
@@ -0,0 +1,100 @@
+package ux
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/Zebbeni/protozoa/simulation"
+)
+
+const (
+	minReplaySpeed   = 1.0 / 16.0
+	maxReplaySpeed   = 64.0
+	replaySeekCycles = 100
+)
+
+// ReplayControls plays back a recorded simulation, with keys to pause, seek
+// and change the playback speed
+type ReplayControls struct {
+	replay *simulation.Replay
+	grid   *Grid
+
+	// speed is the number of recorded cycles played per update, and progress
+	// accumulates fractions of a cycle when playing slower than one per update
+	speed    float64
+	progress float64
+}
+
+// NewReplayInterface returns an Interface that plays back a recording instead
+// of running a simulation
+func NewReplayInterface(replay *simulation.Replay) *Interface {
+	i := NewInterface(replay.Simulation())
+	i.replay = &ReplayControls{
+		replay: replay,
+		grid:   i.grid,
+		speed:  1,
+	}
+	i.panel.replay = i.replay
+	i.setTuning(NewTuningControls(replay.Simulation(), true))
+	return i
+}
+
+func (c *ReplayControls) handleKeyboard() error {
+	sim := c.replay.Simulation()
+	switch {
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowRight):
+		return c.seek(sim.Cycle() + replaySeekCycles)
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowLeft):
+		return c.seek(sim.Cycle() - replaySeekCycles)
+	case inpututil.IsKeyJustReleased(ebiten.KeyHome):
+		return c.seek(c.replay.FirstCycle())
+	case inpututil.IsKeyJustReleased(ebiten.KeyEnd):
+		return c.seek(c.replay.LastCycle())
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowUp):
+		c.speed = math.Min(c.speed*2, maxReplaySpeed)
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowDown):
+		c.speed = math.Max(c.speed/2, minReplaySpeed)
+	}
+	return nil
+}
+
+// seek moves the replay to a given cycle and redraws the full grid
+func (c *ReplayControls) seek(cycle int) error {
+	if cycle < c.replay.FirstCycle() {
+		cycle = c.replay.FirstCycle()
+	}
+	c.progress = 0
+	c.grid.doRefresh = true
+	return c.replay.Seek(cycle)
+}
+
+// update plays any recorded cycles stepped through while paused, or as many
+// as the current speed allows while playing, pausing once the end of the
+// recording is reached
+func (c *ReplayControls) update(steps int) error {
+	sim := c.replay.Simulation()
+	if !sim.IsPaused() {
+		c.progress += c.speed
+		steps = int(c.progress)
+		c.progress -= float64(steps)
+	}
+	for ; steps > 0; steps-- {
+		more, err := c.replay.Step()
+		if err != nil {
+			return err
+		}
+		if !more {
+			sim.Pause(true)
+			c.progress = 0
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *ReplayControls) status() string {
+	return fmt.Sprintf("REPLAY: %6d / %d   SPEED: %gx", c.replay.Simulation().Cycle(), c.replay.LastCycle(), c.speed)
+}
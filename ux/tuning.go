@@ -0,0 +1,156 @@
+package ux
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	c "github.com/Zebbeni/protozoa/config"
+	r "github.com/Zebbeni/protozoa/resources"
+	"github.com/Zebbeni/protozoa/simulation"
+)
+
+const (
+	tuningRowsYOffset  = selectedYOffset + 40
+	tuningRowHeight    = 20
+	tuningSliderX      = 240
+	tuningSliderWidth  = panelWidth - padding - tuningSliderX
+	tuningSliderHeight = 6
+	// tuningLargeStep is the number of steps a setting changes by with [Shift]
+	tuningLargeStep = 10
+)
+
+var (
+	sliderColor         = color.Gray{Y: 60}
+	selectedSliderColor = color.Gray{Y: 110}
+)
+
+// TuningControls shows the settings that can be changed while a simulation
+// runs, with a slider for each. Changes are applied at the beginning of the
+// next cycle. Replays show the recorded settings but can't change them.
+type TuningControls struct {
+	simulation *simulation.Simulation
+	isReadOnly bool
+
+	// selected is the index of the setting in c.Tunables changed by the keys
+	selected int
+}
+
+func NewTuningControls(sim *simulation.Simulation, isReadOnly bool) *TuningControls {
+	return &TuningControls{simulation: sim, isReadOnly: isReadOnly}
+}
+
+func (t *TuningControls) handleKeyboard() {
+	if t.isReadOnly {
+		return
+	}
+	switch {
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowUp):
+		t.selected = (t.selected + len(c.Tunables) - 1) % len(c.Tunables)
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowDown):
+		t.selected = (t.selected + 1) % len(c.Tunables)
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowRight):
+		t.adjust(1)
+	case inpututil.IsKeyJustReleased(ebiten.KeyArrowLeft):
+		t.adjust(-1)
+	}
+}
+
+// handleMouse sets a setting from the position of the cursor on its slider
+// while the left mouse button is held down
+func (t *TuningControls) handleMouse(mouseX, mouseY int) {
+	if t.isReadOnly || !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	// each row extends a little below its text's baseline
+	rowTop := tuningRowsYOffset - tuningRowHeight + 5
+	row := (mouseY - rowTop) / tuningRowHeight
+	if mouseY < rowTop || row >= len(c.Tunables) {
+		return
+	}
+	if mouseX < tuningSliderX-padding || mouseX > tuningSliderX+tuningSliderWidth+padding {
+		return
+	}
+	t.selected = row
+	tunable := c.Tunables[row]
+	fraction := math.Max(0, math.Min(1, float64(mouseX-tuningSliderX)/float64(tuningSliderWidth)))
+	t.set(tunable, tunable.Min+fraction*(tunable.Max-tunable.Min))
+}
+
+// adjust changes the selected setting by a number of steps, or ten times as
+// many while [Shift] is held
+func (t *TuningControls) adjust(steps int) {
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		steps *= tuningLargeStep
+	}
+	tunable := c.Tunables[t.selected]
+	t.set(tunable, t.value(tunable)+float64(steps)*tunable.Step)
+}
+
+// set requests a change to a setting, rounded to its step and kept within its
+// range
+func (t *TuningControls) set(tunable c.Tunable, value float64) {
+	value = tunable.Min + math.Round((value-tunable.Min)/tunable.Step)*tunable.Step
+	value = math.Max(tunable.Min, math.Min(tunable.Max, value))
+	if current, _ := t.simulation.Config().Setting(tunable.Key); current == value {
+		if _, pending := t.simulation.PendingSetting(tunable.Key); !pending {
+			return
+		}
+	}
+	t.simulation.ChangeSetting(tunable.Key, value)
+}
+
+// value returns the value a setting has, or will have once a pending change
+// is applied
+func (t *TuningControls) value(tunable c.Tunable) float64 {
+	if value, pending := t.simulation.PendingSetting(tunable.Key); pending {
+		return value
+	}
+	value, _ := t.simulation.Config().Setting(tunable.Key)
+	return value
+}
+
+func (t *TuningControls) render(panelImage *ebiten.Image) {
+	title := "SETTINGS"
+	if !t.isReadOnly {
+		title += "   [Up/Down] Select  [Left/Right] Change\n           [Shift] Change x10  * = next cycle"
+	}
+	text.Draw(panelImage, title, r.FontSourceCodePro10, selectedXOffset, selectedYOffset, color.White)
+
+	for i, tunable := range c.Tunables {
+		y := tuningRowsYOffset + i*tuningRowHeight
+		value := t.value(tunable)
+		marker := " "
+		if _, pending := t.simulation.PendingSetting(tunable.Key); pending {
+			marker = "*"
+		}
+		label := fmt.Sprintf("%-17s%10s%s", tunable.Name, formatTunable(tunable, value), marker)
+		if i == t.selected && !t.isReadOnly {
+			label = ">" + label
+		} else {
+			label = " " + label
+		}
+		text.Draw(panelImage, label, r.FontSourceCodePro10, selectedXOffset, y, color.White)
+
+		barColor := sliderColor
+		if i == t.selected && !t.isReadOnly {
+			barColor = selectedSliderColor
+		}
+		top := float64(y - tuningSliderHeight - 1)
+		fraction := math.Max(0, math.Min(1, (value-tunable.Min)/(tunable.Max-tunable.Min)))
+		ebitenutil.DrawRect(panelImage, tuningSliderX, top, tuningSliderWidth, tuningSliderHeight, barColor)
+		ebitenutil.DrawRect(panelImage, tuningSliderX, top, fraction*tuningSliderWidth, tuningSliderHeight, color.White)
+	}
+}
+
+// formatTunable formats a setting's value with as many decimal places as its
+// step needs
+func formatTunable(tunable c.Tunable, value float64) string {
+	decimals := int(math.Max(0, math.Ceil(-math.Log10(tunable.Step))))
+	return fmt.Sprintf("%.*f", decimals, value)
+}